@@ -0,0 +1,43 @@
+package daygo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskActionRepo interface {
+	InsertTaskAction(context.Context, TaskActionRecord) (ExistingTaskActionRecord, error)
+	// CountByAction counts the actions taken within range per group and action
+	CountByAction(ctx context.Context, min, max time.Time, groupBy StatsGroup) ([]TaskActionCountRecord, error)
+	// DeleteLastTaskAction deletes the latest action of the kind taken on the task
+	DeleteLastTaskAction(ctx context.Context, taskID uuid.UUID, action TaskAction) (ExistingTaskActionRecord, error)
+}
+
+type TaskAction string
+
+const (
+	TaskActionSkip    TaskAction = "skip"    // task was requeued instead of worked on
+	TaskActionDiscard TaskAction = "discard" // task was deleted
+)
+
+// TaskActionRecord represents something done to a task that leaves no trace on
+// the task itself. The name is kept since the task may no longer exist.
+type TaskActionRecord struct {
+	TaskID uuid.UUID
+	Name   string
+	Action TaskAction
+}
+
+type ExistingTaskActionRecord struct {
+	TaskActionRecord
+	ID        int
+	CreatedAt time.Time
+}
+
+type TaskActionCountRecord struct {
+	Group  string
+	Action TaskAction
+	Count  int
+}
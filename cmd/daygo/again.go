@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/benjamonnguyen/daygo/sqlite"
+)
+
+const (
+	againUsage = "usage: /again [search]"
+	// maxTaskNameSuggestions is how many past task names are suggested as input
+	maxTaskNameSuggestions = 500
+	// maxAgainMatches is how many of the best matching tasks /again picks from
+	maxAgainMatches = 10
+)
+
+// findPastTask returns the task that ended last if query is empty. Otherwise
+// it returns the started task named query or, failing that, the started task
+// that best matches it.
+func findPastTask(ctx context.Context, taskSvc TaskSvc, query string) (Task, error) {
+	if query == "" {
+		t, err := taskSvc.GetLastEndedTask(ctx)
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return Task{}, fmt.Errorf("no ended tasks")
+		}
+		return t, err
+	}
+
+	tasks, err := taskSvc.SearchTasks(ctx, query, maxAgainMatches)
+	if err != nil {
+		return Task{}, err
+	}
+	var best *Task
+	for i, t := range tasks {
+		if t.StartedAt.IsZero() {
+			continue
+		}
+		if strings.EqualFold(t.Name, query) || strings.EqualFold(withoutTags(t.Name), query) {
+			return t, nil
+		}
+		if best == nil {
+			best = &tasks[i]
+		}
+	}
+	if best == nil {
+		return Task{}, fmt.Errorf("no past tasks matching %q", query)
+	}
+	return *best, nil
+}
+
+// taskNameSuggestions completes task names when starting, queueing or redoing a task
+func taskNameSuggestions(names []string) []string {
+	suggestions := make([]string, 0, 3*len(names))
+	for _, prefix := range []string{"", "/a ", "/again "} {
+		for _, name := range names {
+			suggestions = append(suggestions, prefix+name)
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFindPastTask(t *testing.T) {
+	ended := newExportedTasks()
+	queued := TaskFromName("weekly report")
+	weekly := ended[1]
+	weekly.Name = "weekly report #work"
+	tests := []struct {
+		name    string
+		query   string
+		svc     *fakeTaskSvc
+		want    string
+		wantErr bool
+	}{
+		{"last ended", "", &fakeTaskSvc{ended: ended}, "finish report #work", false},
+		{"no ended tasks", "", &fakeTaskSvc{}, "", true},
+		{"best match", "report", &fakeTaskSvc{found: []Task{queued, ended[0], weekly}}, "write report #work", false},
+		{"exact name without tags", "Weekly Report", &fakeTaskSvc{found: []Task{queued, ended[0], weekly}}, "weekly report #work", false},
+		{"only queued matches", "weekly", &fakeTaskSvc{found: []Task{queued}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := findPastTask(context.Background(), tt.svc, tt.query)
+
+			// assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if got.Name != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got.Name)
+			}
+		})
+	}
+}
+
+func TestRememberTaskName(t *testing.T) {
+	// arrange
+	m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{})
+	m.taskNames = []string{"review PR", "write report", "water plants"}
+
+	// act
+	m.rememberTaskName("water plants")
+
+	// assert
+	if want := []string{"water plants", "review PR", "write report"}; !slices.Equal(m.taskNames, want) {
+		t.Fatalf("want %v, got %v", want, m.taskNames)
+	}
+	if got := m.userinput.AvailableSuggestions(); !slices.Contains(got, "/again review PR") {
+		t.Fatalf("want /again suggestion, got %v", got)
+	}
+}
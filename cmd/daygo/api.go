@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// API exposes the running program over a unix socket as JSON-RPC 1.0 methods
+// named "Daygo.<Method>", e.g.
+//
+//	{"method": "Daygo.QueueTask", "params": [{"name": "write report #work"}], "id": 1}
+//
+// Requests are forwarded to the program and answered once it handles them.
+type API struct {
+	send    func(tea.Msg)
+	timeout time.Duration
+}
+
+type APIMethod int
+
+const (
+	APIQueueTask APIMethod = iota
+	APIGetCurrentTask
+	APIAddNote
+	APIEndTask
+	APISetFilter
+)
+
+type APIResult struct {
+	Task   *TaskJSON
+	Filter string
+	Err    error
+}
+
+type APITaskArgs struct {
+	Name string `json:"name"`
+}
+
+type APINoteArgs struct {
+	Text string `json:"text"`
+}
+
+type APIFilterArgs struct {
+	Tag string `json:"tag"`
+}
+
+type APIEmptyArgs struct{}
+
+type APITaskReply struct {
+	// Task is nil if there is no current task
+	Task *TaskJSON `json:"task"`
+}
+
+type APIFilterReply struct {
+	Tag string `json:"tag"`
+}
+
+func (a *API) QueueTask(args APITaskArgs, reply *APITaskReply) error {
+	if args.Name == "" {
+		return fmt.Errorf("provide name")
+	}
+	res, err := a.call(APIQueueTask, args.Name)
+	reply.Task = res.Task
+	return err
+}
+
+func (a *API) GetCurrentTask(_ APIEmptyArgs, reply *APITaskReply) error {
+	res, err := a.call(APIGetCurrentTask, "")
+	reply.Task = res.Task
+	return err
+}
+
+func (a *API) AddNote(args APINoteArgs, reply *APITaskReply) error {
+	if args.Text == "" {
+		return fmt.Errorf("provide text")
+	}
+	res, err := a.call(APIAddNote, args.Text)
+	reply.Task = res.Task
+	return err
+}
+
+func (a *API) EndTask(_ APIEmptyArgs, reply *APITaskReply) error {
+	res, err := a.call(APIEndTask, "")
+	reply.Task = res.Task
+	return err
+}
+
+func (a *API) SetFilter(args APIFilterArgs, reply *APIFilterReply) error {
+	res, err := a.call(APISetFilter, args.Tag)
+	reply.Tag = res.Filter
+	return err
+}
+
+func (a *API) call(method APIMethod, arg string) (APIResult, error) {
+	reply := make(chan APIResult, 1)
+	a.send(APIRequestMsg{
+		method: method,
+		arg:    arg,
+		reply:  reply,
+	})
+	select {
+	case res := <-reply:
+		return res, res.Err
+	case <-time.After(a.timeout):
+		return APIResult{}, fmt.Errorf("timed out waiting for daygo")
+	}
+}
+
+// listenAPI listens on the unix socket at path, replacing a stale socket left
+// by a previous run
+func listenAPI(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %s is in use by another daygo", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// serveAPI serves JSON-RPC requests on ln until it is closed
+func serveAPI(ln net.Listener, api *API) error {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Daygo", api); err != nil {
+		return err
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func startTestAPI(t *testing.T, send func(tea.Msg)) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "daygo.sock")
+	ln, err := listenAPI(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go serveAPI(ln, &API{send: send, timeout: 100 * time.Millisecond}) //nolint:errcheck
+	return path
+}
+
+func TestAPI_ForwardsRequests(t *testing.T) {
+	// arrange
+	var got APIRequestMsg
+	path := startTestAPI(t, func(msg tea.Msg) {
+		got = msg.(APIRequestMsg)
+		got.reply <- APIResult{Task: &TaskJSON{Name: got.arg}}
+	})
+	client, err := jsonrpc.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	// act
+	var reply APITaskReply
+	err = client.Call("Daygo.QueueTask", APITaskArgs{Name: "write report #work"}, &reply)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if got.method != APIQueueTask {
+		t.Fatalf("want %v, got %v", APIQueueTask, got.method)
+	}
+	if reply.Task == nil || reply.Task.Name != "write report #work" {
+		t.Fatalf("want %q, got %+v", "write report #work", reply.Task)
+	}
+}
+
+func TestAPI_TimesOutWithoutReply(t *testing.T) {
+	// arrange
+	path := startTestAPI(t, func(tea.Msg) {})
+	client, err := jsonrpc.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	// act
+	var reply APITaskReply
+	err = client.Call("Daygo.GetCurrentTask", APIEmptyArgs{}, &reply)
+
+	// assert
+	if err == nil {
+		t.Fatalf("want timeout error, got nil")
+	}
+}
+
+func TestListenAPI_ReplacesStaleSocket(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "daygo.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	// act
+	ln, err := listenAPI(path)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	_ = ln.Close()
+}
+
+func TestListenAPI_SocketInUse(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "daygo.sock")
+	ln, err := listenAPI(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close() //nolint:errcheck
+
+	// act
+	_, err = listenAPI(path)
+
+	// assert
+	if err == nil {
+		t.Fatalf("want error, got nil")
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/benjamonnguyen/daygo/sqlite"
+)
+
+// cliOutput is the result of a non-interactive command
+type cliOutput struct {
+	message string
+	Ended   *TaskJSON `json:"ended,omitempty"`
+	Started *TaskJSON `json:"started,omitempty"`
+	Queued  *TaskJSON `json:"queued,omitempty"`
+	Deleted *TaskJSON `json:"deleted,omitempty"`
+	Current *TaskJSON `json:"current,omitempty"`
+}
+
+func (o cliOutput) print(w io.Writer, asJSON bool) error {
+	if !asJSON {
+		if o.message == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, o.message)
+		return err
+	}
+	return json.NewEncoder(w).Encode(o)
+}
+
+func printCLIError(w io.Writer, err error, asJSON bool) {
+	if !asJSON {
+		fmt.Fprintln(w, err) //nolint:errcheck
+		return
+	}
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func taskJSON(t Task) *TaskJSON {
+	j := t.JSON()
+	return &j
+}
+
+// runTaskCommand applies a TUI command to the persisted current task
+func runTaskCommand(ctx context.Context, taskSvc TaskSvc, strategy DequeueStrategy, cmd, arg string) (cliOutput, error) {
+	now := time.Now()
+	getCurrentTask := taskSvc.GetCurrentTask
+	if cmd == "/status" {
+		// rendered on every shell prompt, so it mustn't write or wait on the
+		// write lock held by a running TUI
+		getCurrentTask = taskSvc.PeekCurrentTask
+	}
+	current, err := getCurrentTask(ctx)
+	if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+		return cliOutput{}, err
+	}
+	hasCurrent := err == nil
+
+	var out cliOutput
+	switch cmd {
+	case "/n":
+		next := TaskFromName(arg)
+		if arg == "" {
+			var ok bool
+			if next, ok, err = dequeueTask(ctx, taskSvc, strategy); err != nil {
+				return cliOutput{}, err
+			} else if !ok {
+				return cliOutput{}, fmt.Errorf("task queue is empty")
+			}
+		}
+		var started Task
+		if hasCurrent {
+			var ended Task
+			ended, started, err = taskSvc.SwitchTask(ctx, endingAt(current, now), startingAt(next, now))
+			if err != nil {
+				return cliOutput{}, err
+			}
+			out.Ended = taskJSON(ended)
+		} else if started, err = taskSvc.StartTask(ctx, startingAt(next, now)); err != nil {
+			return cliOutput{}, err
+		}
+		out.Started = taskJSON(started)
+		out.message = fmt.Sprintf(`Started "%s"`, started.Name)
+	case "/x":
+		if !hasCurrent {
+			return cliOutput{}, fmt.Errorf("no current task to delete")
+		}
+		if _, err := taskSvc.DeleteTask(ctx, current.ID); err != nil {
+			return cliOutput{}, err
+		}
+		out.Deleted = taskJSON(current)
+		out.message = fmt.Sprintf(`Deleted "%s"`, current.Name)
+		next, ok, err := dequeueTask(ctx, taskSvc, strategy)
+		if err != nil {
+			return cliOutput{}, err
+		}
+		if ok {
+			started, err := taskSvc.StartTask(ctx, startingAt(next, now))
+			if err != nil {
+				return cliOutput{}, err
+			}
+			out.Started = taskJSON(started)
+			out.message += fmt.Sprintf(`, started "%s"`, started.Name)
+		}
+	case "/k":
+		if !hasCurrent {
+			return cliOutput{}, fmt.Errorf("no current task to skip")
+		}
+		next, ok, err := dequeueTask(ctx, taskSvc, strategy)
+		if err != nil {
+			return cliOutput{}, err
+		} else if !ok {
+			return cliOutput{}, fmt.Errorf("task queue is empty")
+		}
+		current.TimeBlockAt = time.Time{}
+		queued, started, err := taskSvc.SkipToTask(ctx, current, startingAt(next, now))
+		if err != nil {
+			return cliOutput{}, err
+		}
+		out.Queued = taskJSON(queued)
+		out.Started = taskJSON(started)
+		out.message = fmt.Sprintf(`Skipped "%s", started "%s"`, queued.Name, started.Name)
+	case "/note":
+		if arg == "" {
+			return cliOutput{}, fmt.Errorf("usage: daygo /note <text>")
+		}
+		if !hasCurrent {
+			return cliOutput{}, fmt.Errorf("no current task to add note to")
+		}
+		n, err := taskSvc.AddNote(ctx, current.ID, arg)
+		if err != nil {
+			return cliOutput{}, err
+		}
+		current.Notes = append(current.Notes, n)
+		out.Current = taskJSON(current)
+		out.message = fmt.Sprintf(`Noted "%s" on "%s"`, n.Name, current.Name)
+	case "/end":
+		if !hasCurrent {
+			return cliOutput{}, fmt.Errorf("no current task to end")
+		}
+		ended, err := taskSvc.EndTask(ctx, endingAt(current, now))
+		if err != nil {
+			return cliOutput{}, err
+		}
+		out.Ended = taskJSON(ended)
+		out.message = fmt.Sprintf(`Ended "%s" after %s`, ended.Name, formatDuration(ended.Duration()))
+	case "/status":
+		if !hasCurrent {
+			// printed on every prompt, so no task isn't an error
+			break
+		}
+		out.Current = taskJSON(current)
+		if arg != "" {
+			if out.message, err = renderStatus(arg, current, now); err != nil {
+				return cliOutput{}, fmt.Errorf("invalid status format: %w", err)
+			}
+			break
+		}
+		out.message = fmt.Sprintf(`"%s" for %s`, current.Name, formatDuration(current.Elapsed(now)))
+		if current.IsPaused() {
+			out.message += " (paused)"
+		}
+		if s := newStatusData(current, now); s.Remaining != "" {
+			out.message += fmt.Sprintf(", %s left", s.Remaining)
+		}
+	default:
+		return cliOutput{}, fmt.Errorf("unknown command %s", cmd)
+	}
+	return out, nil
+}
+
+// forwardTaskCommand sends a command that changes the current task to the
+// daygo serving the API on socketPath, if any, rather than changing the
+// persisted task from under it. Commands the API has no method for are refused.
+// Returns false if no daygo is running.
+func forwardTaskCommand(socketPath, cmd, arg string) (cliOutput, bool, error) {
+	if socketPath == "" || cmd == "/status" {
+		return cliOutput{}, false, nil
+	}
+	client, err := jsonrpc.Dial("unix", socketPath)
+	if err != nil {
+		return cliOutput{}, false, nil
+	}
+	defer client.Close() //nolint:errcheck
+
+	var out cliOutput
+	var reply APITaskReply
+	switch cmd {
+	case "/note":
+		if arg == "" {
+			return cliOutput{}, true, fmt.Errorf("usage: daygo /note <text>")
+		}
+		if err := client.Call("Daygo.AddNote", APINoteArgs{Text: arg}, &reply); err != nil {
+			return cliOutput{}, true, err
+		}
+		out.Current = reply.Task
+		out.message = fmt.Sprintf(`Noted "%s" on "%s"`, arg, reply.Task.Name)
+	case "/end":
+		if err := client.Call("Daygo.EndTask", APIEmptyArgs{}, &reply); err != nil {
+			return cliOutput{}, true, err
+		}
+		out.Ended = reply.Task
+		out.message = fmt.Sprintf(`Ended "%s" after %s`, reply.Task.Name, formatDuration(time.Duration(reply.Task.DurationSeconds)*time.Second))
+	default:
+		return cliOutput{}, true, fmt.Errorf("daygo is running, use %s there instead", cmd)
+	}
+	return out, true, nil
+}
+
+// dequeueTask returns the next task in the queue; false if the queue is empty
+func dequeueTask(ctx context.Context, taskSvc TaskSvc, strategy DequeueStrategy) (Task, bool, error) {
+	pending, err := taskSvc.GetPendingTasks(ctx)
+	if err != nil {
+		return Task{}, false, err
+	}
+	q := NewTaskQueue(pending)
+	q.SetStrategy(strategy)
+	if q.Size() == 0 {
+		return Task{}, false, nil
+	}
+	return q.Dequeue(), true, nil
+}
+
+// startingAt returns t to be started at now
+func startingAt(t Task, now time.Time) Task {
+	t.StartedAt = now
+	t.TimeBlockAt = time.Time{}
+	return t
+}
+
+// endingAt returns t to be ended at now
+func endingAt(t Task, now time.Time) Task {
+	t.EndedAt = now
+	if t.IsPaused() {
+		t.TogglePause(now)
+	}
+	return t
+}
+
+// parseArgs parses flags interspersed with positional args, returning the positional args
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/benjamonnguyen/daygo/sqlite"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+// fakeTaskSvc keeps tasks in memory; unimplemented methods panic
+type fakeTaskSvc struct {
+	TaskSvc
+	current *Task
+	pending []Task
+	ended   []Task
+	deleted []uuid.UUID
+	skipped []uuid.UUID
+	notes   []Note
+	stats   map[daygo.StatsGroup][]TaskStats
+	// found is returned by SearchTasks
+	found  []Task
+	undone []UndoStep
+	edited []TaskEdit
+	// editErr is returned by EditTask
+	editErr error
+	logged  []Task
+	pauses  []Pause
+	// requeueSplit records the requeueSubtasks passed to SplitTask
+	requeueSplit bool
+}
+
+func (s *fakeTaskSvc) SavePauses(_ context.Context, _ uuid.UUID, pauses []Pause) ([]Pause, error) {
+	saved := slices.Clone(pauses)
+	for i := range saved {
+		if saved[i].ID == 0 {
+			saved[i].ID = i + 1
+		}
+	}
+	s.pauses = saved
+	return saved, nil
+}
+
+func (s *fakeTaskSvc) LogTask(_ context.Context, t Task) (Task, error) {
+	s.logged = append(s.logged, t)
+	return t, nil
+}
+
+func (s *fakeTaskSvc) EditTask(_ context.Context, e TaskEdit) (Task, error) {
+	if s.editErr != nil {
+		return Task{}, s.editErr
+	}
+	s.edited = append(s.edited, e)
+	return e.After, nil
+}
+
+func (s *fakeTaskSvc) Undo(_ context.Context, steps []UndoStep) error {
+	s.undone = append(s.undone, steps...)
+	return nil
+}
+
+func (s *fakeTaskSvc) SearchTasks(context.Context, string, int) ([]Task, error) {
+	return s.found, nil
+}
+
+func (s *fakeTaskSvc) GetLastEndedTask(context.Context) (Task, error) {
+	if len(s.ended) == 0 {
+		return Task{}, fmt.Errorf("no ended task: %w", sqlite.ErrNotFound)
+	}
+	return s.ended[len(s.ended)-1], nil
+}
+
+func (s *fakeTaskSvc) GetTasksByStartTime(context.Context, time.Time, time.Time) ([]Task, error) {
+	return s.ended, nil
+}
+
+func (s *fakeTaskSvc) GetCurrentTask(context.Context) (Task, error) {
+	if s.current == nil {
+		return Task{}, fmt.Errorf("no current task: %w", sqlite.ErrNotFound)
+	}
+	return *s.current, nil
+}
+
+func (s *fakeTaskSvc) PeekCurrentTask(ctx context.Context) (Task, error) {
+	return s.GetCurrentTask(ctx)
+}
+
+func (s *fakeTaskSvc) GetPendingTasks(context.Context) ([]Task, error) {
+	return s.pending, nil
+}
+
+func (s *fakeTaskSvc) StartTask(_ context.Context, t Task) (Task, error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	s.current = &t
+	return t, nil
+}
+
+func (s *fakeTaskSvc) SaveTaskRecord(_ context.Context, t Task) (Task, error) {
+	s.current = &t
+	return t, nil
+}
+
+func (s *fakeTaskSvc) EndTask(_ context.Context, t Task) (Task, error) {
+	s.current = nil
+	s.ended = append(s.ended, t)
+	return t, nil
+}
+
+func (s *fakeTaskSvc) SwitchTask(ctx context.Context, ended, next Task) (Task, Task, error) {
+	ended, _ = s.EndTask(ctx, ended)
+	next, _ = s.StartTask(ctx, next)
+	return ended, next, nil
+}
+
+func (s *fakeTaskSvc) SkipToTask(ctx context.Context, skipped, next Task) (Task, Task, error) {
+	skipped, _ = s.SkipTask(ctx, skipped)
+	next, _ = s.StartTask(ctx, next)
+	return skipped, next, nil
+}
+
+func (s *fakeTaskSvc) SplitTask(ctx context.Context, ended Task, followUp string, requeueSubtasks bool) (Task, []Task, error) {
+	s.requeueSplit = requeueSubtasks
+	ended, _ = s.EndTask(ctx, ended)
+	t := TaskFromName(followUp)
+	t.ContinuesID = ended.ID
+	queued, _ := s.QueueTask(ctx, t)
+	return queued, nil, nil
+}
+
+func (s *fakeTaskSvc) QueueTask(_ context.Context, t Task) (Task, error) {
+	t.StartedAt = time.Time{}
+	if t.QueuedAt.IsZero() {
+		t.QueuedAt = time.Now()
+	}
+	s.pending = append(s.pending, t)
+	return t, nil
+}
+
+func (s *fakeTaskSvc) SkipTask(ctx context.Context, t Task) (Task, error) {
+	t.QueuedAt = time.Time{}
+	s.skipped = append(s.skipped, t.ID)
+	return s.QueueTask(ctx, t)
+}
+
+func (s *fakeTaskSvc) ImportTask(_ context.Context, t Task) (Task, error) {
+	s.ended = append(s.ended, t)
+	return t, nil
+}
+
+func (s *fakeTaskSvc) AddNote(_ context.Context, parentID uuid.UUID, text string) (Note, error) {
+	n := Note(daygo.TaskRecord{Name: text, ParentID: parentID, StartedAt: time.Now()})
+	s.notes = append(s.notes, n)
+	return n, nil
+}
+
+func (s *fakeTaskSvc) DeleteTask(_ context.Context, id uuid.UUID) ([]daygo.ExistingTaskRecord, error) {
+	s.deleted = append(s.deleted, id)
+	return nil, nil
+}
+
+func (s *fakeTaskSvc) GetStats(_ context.Context, _, _ time.Time, groupBy daygo.StatsGroup) ([]TaskStats, error) {
+	return s.stats[groupBy], nil
+}
+
+func newPersistedTask(name string) Task {
+	t := newQueuedTask(name, time.Now())
+	t.ID = uuid.New()
+	return t
+}
+
+func TestRunTaskCommand_NextEndsCurrentAndDequeues(t *testing.T) {
+	// arrange
+	curr := newPersistedTask("write report")
+	curr.StartedAt = time.Now().Add(-time.Hour)
+	svc := &fakeTaskSvc{
+		current: &curr,
+		pending: []Task{newPersistedTask("review PR")},
+	}
+
+	// act
+	out, err := runTaskCommand(context.Background(), svc, DequeueStrategy{}, "/n", "")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(svc.ended) != 1 || svc.ended[0].EndedAt.IsZero() {
+		t.Fatalf("want ended %q, got %+v", curr.Name, svc.ended)
+	}
+	if svc.current == nil || svc.current.Name != "review PR" || svc.current.StartedAt.IsZero() {
+		t.Fatalf("want started %q, got %+v", "review PR", svc.current)
+	}
+	if out.Ended == nil || out.Started == nil {
+		t.Fatalf("want ended and started in output, got %+v", out)
+	}
+}
+
+func TestRunTaskCommand_SkipRequiresQueuedTask(t *testing.T) {
+	// arrange
+	curr := newPersistedTask("write report")
+	curr.StartedAt = time.Now()
+	svc := &fakeTaskSvc{current: &curr}
+
+	// act
+	_, err := runTaskCommand(context.Background(), svc, DequeueStrategy{}, "/k", "")
+
+	// assert
+	if err == nil {
+		t.Fatalf("want error, got nil")
+	}
+	if svc.current == nil || svc.current.ID != curr.ID {
+		t.Fatalf("want current task unchanged, got %+v", svc.current)
+	}
+}
+
+func TestRunTaskCommand_SkipRequeuesCurrent(t *testing.T) {
+	// arrange
+	curr := newPersistedTask("write report")
+	curr.StartedAt = time.Now()
+	next := newPersistedTask("review PR")
+	svc := &fakeTaskSvc{
+		current: &curr,
+		pending: []Task{next},
+	}
+
+	// act
+	out, err := runTaskCommand(context.Background(), svc, DequeueStrategy{}, "/k", "")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if svc.current.ID != next.ID {
+		t.Fatalf("want %q, got %q", next.Name, svc.current.Name)
+	}
+	if out.Queued == nil || out.Queued.ID != curr.ID {
+		t.Fatalf("want queued %q, got %+v", curr.Name, out.Queued)
+	}
+	if len(svc.skipped) != 1 || svc.skipped[0] != curr.ID {
+		t.Fatalf("want skipped %v, got %v", curr.ID, svc.skipped)
+	}
+}
+
+func TestRunTaskCommand_NoCurrentTask(t *testing.T) {
+	for _, cmd := range []string{"/x", "/k", "/end"} {
+		t.Run(cmd, func(t *testing.T) {
+			// act
+			_, err := runTaskCommand(context.Background(), &fakeTaskSvc{}, DequeueStrategy{}, cmd, "")
+
+			// assert
+			if err == nil {
+				t.Fatalf("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestRunTaskCommand_StatusWithoutCurrentTask(t *testing.T) {
+	// arrange
+	var buf bytes.Buffer
+
+	// act
+	out, err := runTaskCommand(context.Background(), &fakeTaskSvc{}, DequeueStrategy{}, "/status", "{{.Name}}")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if err := out.print(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("want no output, got %q", buf.String())
+	}
+}
+
+func TestRunTaskCommand_NotePrintsJSON(t *testing.T) {
+	// arrange
+	curr := newPersistedTask("write report #work")
+	curr.StartedAt = time.Now()
+	svc := &fakeTaskSvc{current: &curr}
+	var buf bytes.Buffer
+
+	// act
+	out, err := runTaskCommand(context.Background(), svc, DequeueStrategy{}, "/note", "blocked on review")
+	if err == nil {
+		err = out.print(&buf, true)
+	}
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	var got cliOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("want JSON, got %q", buf.String())
+	}
+	if got.Current == nil || len(got.Current.Notes) != 1 || got.Current.Notes[0].Text != "blocked on review" {
+		t.Fatalf("want note %q, got %+v", "blocked on review", got.Current)
+	}
+}
+
+func TestForwardTaskCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		cmd           string
+		arg           string
+		wantForwarded bool
+		wantMethod    APIMethod
+		wantErr       bool
+	}{
+		{name: "note", cmd: "/note", arg: "outlined", wantForwarded: true, wantMethod: APIAddNote},
+		{name: "end", cmd: "/end", wantForwarded: true, wantMethod: APIEndTask},
+		{name: "next refused", cmd: "/n", arg: "review PR", wantForwarded: true, wantErr: true},
+		{name: "skip refused", cmd: "/k", wantForwarded: true, wantErr: true},
+		{name: "status read locally", cmd: "/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			sent := make(chan APIMethod, 1)
+			path := startTestAPI(t, func(msg tea.Msg) {
+				req := msg.(APIRequestMsg)
+				sent <- req.method
+				req.reply <- APIResult{Task: &TaskJSON{Name: "write report"}}
+			})
+
+			// act
+			out, forwarded, err := forwardTaskCommand(path, tt.cmd, tt.arg)
+
+			// assert
+			if forwarded != tt.wantForwarded || (err != nil) != tt.wantErr {
+				t.Fatalf("want forwarded %v with error %v, got %v and %v", tt.wantForwarded, tt.wantErr, forwarded, err)
+			}
+			if tt.wantErr || !tt.wantForwarded {
+				if len(sent) != 0 {
+					t.Fatalf("want nothing sent, got %v", <-sent)
+				}
+				return
+			}
+			if got := <-sent; got != tt.wantMethod {
+				t.Fatalf("want %v sent, got %v", tt.wantMethod, got)
+			}
+			if !strings.Contains(out.message, "write report") {
+				t.Fatalf("want message about %q, got %q", "write report", out.message)
+			}
+		})
+	}
+}
+
+func TestForwardTaskCommand_NotRunning(t *testing.T) {
+	// act
+	_, forwarded, err := forwardTaskCommand(filepath.Join(t.TempDir(), "daygo.sock"), "/end", "")
+
+	// assert
+	if forwarded || err != nil {
+		t.Fatalf("want not forwarded, got %v and %v", forwarded, err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// slashCommands are the commands handled by handleInput, completed on tab
+var slashCommands = []string{
+	"/a", "/again", "/done", "/e", "/f", "/find", "/h", "/k", "/log", "/mode",
+	"/n", "/o", "/p", "/pomo", "/recur", "/split", "/sub", "/t", "/u", "/x",
+}
+
+// completion cycles through the suggestions matched when tab was first pressed
+type completion struct {
+	candidates []string
+	i          int
+}
+
+// inputSuggestions returns the whole inputs that value may be completed to:
+// commands, the tag being typed, /f tags, or past task names
+func (m model) inputSuggestions(value string) []string {
+	if strings.HasPrefix(value, "/f ") {
+		return prefixAll("/f ", m.allTags())
+	}
+	if strings.HasPrefix(value, "/") && !strings.Contains(value, " ") {
+		return slashCommands
+	}
+	i := strings.LastIndex(value, " ") + 1
+	if strings.HasPrefix(value[i:], "#") {
+		return prefixAll(value[:i]+"#", m.allTags())
+	}
+	return taskNameSuggestions(m.taskNames)
+}
+
+// allTags returns the tags of queued and past tasks, sorted
+func (m model) allTags() []string {
+	var tags []string
+	if m.taskQueue != nil {
+		tags = append(tags, m.taskQueue.AllTags()...)
+	}
+	for _, name := range m.taskNames {
+		tags = append(tags, extractTags(name)...)
+	}
+	tags = slices.DeleteFunc(tags, func(tag string) bool {
+		return tag == ""
+	})
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+func prefixAll(prefix string, ss []string) []string {
+	prefixed := make([]string, 0, len(ss))
+	for _, s := range ss {
+		prefixed = append(prefixed, prefix+s)
+	}
+	return prefixed
+}
+
+// complete replaces the input with the suggestion shown, or with the next
+// matched suggestion in the direction of step if it was just completed. The
+// input typed is last in the cycle.
+func (m *model) complete(step int) {
+	value := m.userinput.Value()
+	c := &m.completion
+	if len(c.candidates) == 0 || value != c.candidates[c.i] {
+		c.candidates = slices.DeleteFunc(m.userinput.MatchedSuggestions(), func(s string) bool {
+			return strings.EqualFold(s, value)
+		})
+		if len(c.candidates) == 0 {
+			return
+		}
+		// cycle back to what was typed
+		c.candidates = append(c.candidates, value)
+		c.i = 0
+		if step < 0 {
+			c.i = len(c.candidates) - 2
+		}
+	} else {
+		c.i = (c.i + step + len(c.candidates)) % len(c.candidates)
+	}
+	m.userinput.SetValue(c.candidates[c.i])
+	m.userinput.CursorEnd()
+}
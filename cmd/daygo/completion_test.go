@@ -0,0 +1,84 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeInput(m model, s string) model {
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	return updated.(model)
+}
+
+func pressKey(m model, k tea.KeyType) model {
+	updated, _ := m.Update(tea.KeyMsg{Type: k})
+	return updated.(model)
+}
+
+func TestComplete_CyclesCommands(t *testing.T) {
+	// arrange
+	m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{})
+	m = typeInput(m, "/f")
+
+	// act
+	var got []string
+	for range 3 {
+		m = pressKey(m, tea.KeyTab)
+		got = append(got, m.userinput.Value())
+	}
+	m = pressKey(m, tea.KeyShiftTab)
+
+	// assert
+	if want := []string{"/find", "/f", "/find"}; !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if m.userinput.Value() != "/f" {
+		t.Fatalf("want %q, got %q", "/f", m.userinput.Value())
+	}
+}
+
+func TestComplete_Tags(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"tag in task name", "water plants #ho", "water plants #housework"},
+		{"filter argument", "/f wo", "/f work"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{})
+			m.taskQueue = NewTaskQueue([]Task{TaskFromName("vacuum #housework")})
+			m.taskNames = []string{"write report #work"}
+			m = typeInput(m, tt.input)
+
+			// act
+			m = pressKey(m, tea.KeyTab)
+
+			// assert
+			if got := m.userinput.Value(); got != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestComplete_PastTaskNames(t *testing.T) {
+	// arrange
+	m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{})
+	m.rememberTaskName("rerun benchmarks #perf")
+	m = typeInput(m, "/again rer")
+
+	// act
+	ghost := m.userinput.CurrentSuggestion()
+	m = pressKey(m, tea.KeyTab)
+
+	// assert
+	if want := "/again rerun benchmarks #perf"; ghost != want || m.userinput.Value() != want {
+		t.Fatalf("want %q, got ghost %q and value %q", want, ghost, m.userinput.Value())
+	}
+}
@@ -9,19 +9,35 @@ import (
 )
 
 const (
-	KeyDatabaseURL   config.Key = "DAYGO_DB_URL"
-	KeyLogLevel      config.Key = "DAYGO_LOG_LEVEL"
-	KeyLogPath       config.Key = "DAYGO_LOG_PATH"
-	KeyTimeFormat    config.Key = "DAYGO_TIME_FORMAT"
-	KeySyncServerURL config.Key = "DAYGO_SYNC_SERVER_URL"
-	KeySyncRate      config.Key = "DAYGO_SYNC_RATE"
-	KeyCmdTimeout    config.Key = "DAYGO_CMD_TIMEOUT"
+	KeyDatabaseURL       config.Key = "DAYGO_DB_URL"
+	KeyLogLevel          config.Key = "DAYGO_LOG_LEVEL"
+	KeyLogPath           config.Key = "DAYGO_LOG_PATH"
+	KeyTimeFormat        config.Key = "DAYGO_TIME_FORMAT"
+	KeySyncServerURL     config.Key = "DAYGO_SYNC_SERVER_URL"
+	KeySyncRate          config.Key = "DAYGO_SYNC_RATE"
+	KeyCmdTimeout        config.Key = "DAYGO_CMD_TIMEOUT"
+	KeyQueueMode         config.Key = "DAYGO_QUEUE_MODE"
+	KeyRequeueSubtasks   config.Key = "DAYGO_REQUEUE_SUBTASKS"
+	KeyIdleTimeout       config.Key = "DAYGO_IDLE_TIMEOUT"
+	KeyPomoBreakAction   config.Key = "DAYGO_POMO_BREAK_ACTION"
+	KeyTimeBlockWarn     config.Key = "DAYGO_TIME_BLOCK_WARN"
+	KeyNotify            config.Key = "DAYGO_NOTIFY"
+	KeyNotifyCmd         config.Key = "DAYGO_NOTIFY_CMD"
+	KeyHooksDir          config.Key = "DAYGO_HOOKS_DIR"
+	KeySocketPath        config.Key = "DAYGO_SOCKET_PATH"
+	KeyStatusFormat      config.Key = "DAYGO_STATUS_FORMAT"
+	KeyTimesheetRound    config.Key = "DAYGO_TIMESHEET_ROUND"
+	KeyTimesheetProjects config.Key = "DAYGO_TIMESHEET_PROJECTS"
+	KeyHistoryPath       config.Key = "DAYGO_HISTORY_PATH"
 )
 
 var (
 	userHome, _        = os.UserHomeDir()
 	DefaultDatabaseURL = path.Join(userHome, ".daygo", "daygo.db")
 	DefaultLogPath     = path.Join(userHome, ".daygo", "daygo.log")
+	DefaultHooksDir    = path.Join(userHome, ".daygo", "hooks")
+	DefaultSocketPath  = path.Join(userHome, ".daygo", "daygo.sock")
+	DefaultHistoryPath = path.Join(userHome, ".daygo", "history")
 )
 
 func LoadConf(src string) (config.Config, error) {
@@ -58,6 +74,54 @@ func LoadConf(src string) (config.Config, error) {
 			Default:  "3s",
 			Required: true,
 		},
+		{
+			Key:     KeyQueueMode,
+			Default: "fifo",
+		},
+		{
+			Key:     KeyRequeueSubtasks,
+			Default: "false",
+		},
+		{
+			Key:     KeyIdleTimeout,
+			Default: "0",
+		},
+		{
+			Key:     KeyPomoBreakAction,
+			Default: string(PomoBreakContinue),
+		},
+		{
+			Key:     KeyTimeBlockWarn,
+			Default: "5m",
+		},
+		{
+			Key: KeyNotify,
+		},
+		{
+			Key: KeyNotifyCmd,
+		},
+		{
+			Key:     KeyHooksDir,
+			Default: DefaultHooksDir,
+		},
+		{
+			Key:     KeySocketPath,
+			Default: DefaultSocketPath,
+		},
+		{
+			Key: KeyStatusFormat,
+		},
+		{
+			Key:     KeyTimesheetRound,
+			Default: "15m",
+		},
+		{
+			Key: KeyTimesheetProjects,
+		},
+		{
+			Key:     KeyHistoryPath,
+			Default: DefaultHistoryPath,
+		},
 	}
 
 	return env.NewConfig(src, entries...)
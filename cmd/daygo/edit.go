@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const editUsage = "usage: /e <edit> | /e start|end <HHMM>"
+
+// logItem is a task in the task log or, if note >= 0, one of its notes
+type logItem struct {
+	task, note int
+}
+
+// logItems returns the tasks and notes in the task log in display order
+func (m model) logItems() []logItem {
+	var items []logItem
+	for i, t := range m.taskLog {
+		items = append(items, logItem{task: i, note: -1})
+		for j := range t.Notes {
+			items = append(items, logItem{task: i, note: j})
+		}
+	}
+	return items
+}
+
+// itemName returns the name of the task or note
+func (m model) itemName(item logItem) string {
+	t := m.taskLog[item.task]
+	if item.note >= 0 {
+		return t.Notes[item.note].Name
+	}
+	return t.Name
+}
+
+// moveSelection moves the task log cursor by step items, starting from the
+// bottom. Moving past the last item clears the selection. The input is
+// prefilled to edit the selected item.
+func (m *model) moveSelection(step int) {
+	items := m.logItems()
+	pos := len(items)
+	if m.selected != nil {
+		for i, item := range items {
+			if item == *m.selected {
+				pos = i
+			}
+		}
+	}
+	pos = max(0, pos+step)
+	if pos >= len(items) {
+		m.selected = nil
+		return
+	}
+
+	m.selected = &items[pos]
+	m.userinput.SetValue("/e " + m.itemName(items[pos]))
+	m.userinput.CursorEnd()
+}
+
+// editTarget returns the selected item, or the current task's last note or the
+// current task itself
+func (m model) editTarget() (logItem, bool) {
+	if m.selected != nil {
+		return *m.selected, true
+	}
+	t := m.currentTask()
+	if !t.IsPending() {
+		return logItem{}, false
+	}
+	return logItem{task: len(m.taskLog) - 1, note: len(t.Notes) - 1}, true
+}
+
+// editItem renames the item or, given "start|end <HHMM>", moves its start or
+// end to that time of the same day. The task is persisted before the edit is
+// applied to the task log so that invalid times are rejected.
+func (m model) editItem(item logItem, edit string) (tea.Cmd, error) {
+	if item.task >= len(m.taskLog) || item.note >= len(m.taskLog[item.task].Notes) {
+		return nil, fmt.Errorf("item no longer in the task log")
+	}
+	before := cloneTask(m.taskLog[item.task])
+	if m.isStarting(before) {
+		return nil, fmt.Errorf("\"%s\" is still being saved", before.Name)
+	}
+	after := cloneTask(before)
+	if err := applyEdit(&after, item.note, edit); err != nil {
+		return nil, err
+	}
+
+	return func() tea.Msg {
+		timeout, cancel := m.newTimeout()
+		defer cancel()
+		edited, err := m.taskSvc.EditTask(timeout, TaskEdit{Before: before, After: after})
+		if err != nil {
+			return ErrorMsg{err: err}
+		}
+		return TaskEditedMsg{before: before, task: edited, note: item.note, edit: edit}
+	}, nil
+}
+
+// applyEdit applies the edit given to /e to the task or its nth note
+func applyEdit(t *Task, note int, edit string) error {
+	field, value, _ := strings.Cut(edit, " ")
+	switch {
+	case (field == "start" || field == "end") && timeRe.MatchString(value):
+		return setItemTime(t, note, field, value)
+	case note >= 0:
+		t.Notes[note].Name = edit
+	default:
+		t.Name = edit
+		t.Tags = extractTags(edit)
+	}
+	return nil
+}
+
+// applyEditedTask applies a persisted edit to the task as it is now in the task
+// log, which may have changed while the edit was being persisted
+func (m *model) applyEditedTask(msg TaskEditedMsg) {
+	i := slices.IndexFunc(m.taskLog, func(t Task) bool { return sameTask(t, msg.before) })
+	if i < 0 {
+		return
+	}
+	before := cloneTask(m.taskLog[i])
+	after := cloneTask(before)
+	if err := applyEdit(&after, msg.note, msg.edit); err != nil {
+		m.addAlert(colorRed, "%s", err)
+		return
+	}
+	after.ID, after.CreatedAt, after.UpdatedAt = msg.task.ID, msg.task.CreatedAt, msg.task.UpdatedAt
+	m.taskLog[i] = after
+	m.recordUndo(undoEdit, before, time.Now())
+}
+
+// setItemTime sets the start or end of the task, or the start of its nth note
+func setItemTime(t *Task, note int, field, hhmm string) error {
+	if note >= 0 {
+		if field == "end" {
+			return fmt.Errorf("a note ends when the next one starts")
+		}
+		n := &t.Notes[note]
+		at := timeOfDay(n.StartedAt, hhmm)
+		if note > 0 {
+			prev := &t.Notes[note-1]
+			if !at.After(prev.StartedAt) {
+				return fmt.Errorf("note must start after \"%s\"", prev.Name)
+			}
+			prev.EndedAt = at
+		}
+		if note < len(t.Notes)-1 && !at.Before(t.Notes[note+1].StartedAt) {
+			return fmt.Errorf("note must start before \"%s\"", t.Notes[note+1].Name)
+		}
+		n.StartedAt = at
+		return nil
+	}
+
+	if field == "start" {
+		t.StartedAt = timeOfDay(t.StartedAt, hhmm)
+		return nil
+	}
+	if t.EndedAt.IsZero() {
+		return fmt.Errorf("task is pending, end it with /n")
+	}
+	end := timeOfDay(t.EndedAt, hhmm)
+	if n := t.LastNote(); n != nil && n.EndedAt.Equal(t.EndedAt) {
+		n.EndedAt = end
+	}
+	t.EndedAt = end
+	return nil
+}
+
+// timeOfDay returns the time (HHMM) on the same day as day
+func timeOfDay(day time.Time, hhmm string) time.Time {
+	t, _ := time.Parse("1504", hhmm)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
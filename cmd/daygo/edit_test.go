@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newEditModel returns a model with an ended "write report" followed by the
+// current "review PR" with a note
+func newEditModel(svc *fakeTaskSvc) model {
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	ended := newPersistedTask("write report")
+	ended.StartedAt = start
+	ended.EndedAt = start.Add(time.Hour)
+	curr := newPersistedTask("review PR")
+	curr.StartedAt = ended.EndedAt
+	m := NewModel(svc, []Task{ended, curr}, nil, modelOptions{})
+	m.taskQueue = NewTaskQueue(nil)
+	m.addNote("left comments")
+	return m
+}
+
+func TestMoveSelection(t *testing.T) {
+	// arrange
+	m := newEditModel(&fakeTaskSvc{})
+
+	// act
+	var got []string
+	for range 4 {
+		m = pressKey(m, tea.KeyShiftUp)
+		got = append(got, m.userinput.Value())
+	}
+	m = pressKey(m, tea.KeyShiftDown)
+	m = pressKey(m, tea.KeyShiftDown)
+	m = pressKey(m, tea.KeyShiftDown)
+
+	// assert
+	want := []string{"/e left comments", "/e review PR", "/e write report", "/e write report"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	}
+	if m.selected != nil {
+		t.Fatalf("want selection cleared past the last item, got %+v", *m.selected)
+	}
+}
+
+func TestEdit_RenamesLastNote(t *testing.T) {
+	// arrange
+	m := newEditModel(&fakeTaskSvc{})
+
+	// act
+	m, cmd := m.handleInput("/e left review comments")
+	m = applyCmds(m, cmd)
+
+	// assert
+	if got := m.currentTask().LastNote().Name; got != "left review comments" {
+		t.Fatalf("want %q, got %q", "left review comments", got)
+	}
+}
+
+func TestEdit_SelectedTaskEnd(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m := newEditModel(svc)
+	m = pressKey(m, tea.KeyShiftUp)
+	m = pressKey(m, tea.KeyShiftUp)
+	m = pressKey(m, tea.KeyShiftUp)
+
+	// act
+	m, cmd := m.handleInput("/e end 0945")
+	m = applyCmds(m, cmd)
+
+	// assert
+	want := time.Date(2025, 3, 10, 9, 45, 0, 0, time.Local)
+	if got := m.taskLog[0].EndedAt; !got.Equal(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if len(svc.edited) != 1 || !svc.edited[0].Before.EndedAt.Equal(want.Add(15*time.Minute)) {
+		t.Fatalf("want edit persisted, got %+v", svc.edited)
+	}
+}
+
+func TestEdit_KeepsTaskLogIfRejected(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{editErr: errors.New("overlaps")}
+	m := newEditModel(svc)
+	m.selected = &logItem{task: 1, note: -1}
+
+	// act
+	m, cmd := m.handleInput("/e start 0830")
+	msgs := runCmds(cmd)
+
+	// assert
+	if got := m.taskLog[1].StartedAt; !got.Equal(m.taskLog[0].EndedAt) {
+		t.Fatalf("want start unchanged, got %v", got)
+	}
+	if len(msgs) != 1 || len(m.undoLog) != 0 {
+		t.Fatalf("want error and nothing to undo, got %v and %d actions", msgs, len(m.undoLog))
+	}
+	if _, ok := msgs[0].(ErrorMsg); !ok {
+		t.Fatalf("want error, got %v", msgs[0])
+	}
+}
+
+func TestSetItemTime(t *testing.T) {
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	newTask := func(ended bool) Task {
+		task := TaskFromName("write report")
+		task.StartedAt = start
+		for i, name := range []string{"outlined", "drafted"} {
+			n := Note{Name: name, StartedAt: start.Add(time.Duration(i+1) * 10 * time.Minute)}
+			task.Notes = append(task.Notes, n)
+		}
+		task.Notes[0].EndedAt = task.Notes[1].StartedAt
+		if ended {
+			task.EndedAt = start.Add(time.Hour)
+			task.Notes[1].EndedAt = task.EndedAt
+		}
+		return task
+	}
+	tests := []struct {
+		name    string
+		ended   bool
+		note    int
+		field   string
+		hhmm    string
+		wantErr bool
+	}{
+		{"task start", false, -1, "start", "0845", false},
+		{"pending task end", false, -1, "end", "0945", true},
+		{"note start", false, 1, "start", "0915", false},
+		{"note before previous", false, 1, "start", "0905", true},
+		{"note end", true, 0, "end", "0915", true},
+		{"ended task end", true, -1, "end", "0950", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			task := newTask(tt.ended)
+
+			// act
+			err := setItemTime(&task, tt.note, tt.field, tt.hhmm)
+
+			// assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", task)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			want := timeOfDay(start, tt.hhmm)
+			switch {
+			case tt.note >= 0:
+				if !task.Notes[tt.note].StartedAt.Equal(want) || !task.Notes[tt.note-1].EndedAt.Equal(want) {
+					t.Fatalf("want note and previous note's end at %v, got %+v", want, task.Notes)
+				}
+			case tt.field == "start":
+				if !task.StartedAt.Equal(want) {
+					t.Fatalf("want %v, got %v", want, task.StartedAt)
+				}
+			default:
+				if !task.EndedAt.Equal(want) || !task.LastNote().EndedAt.Equal(want) {
+					t.Fatalf("want task and last note to end at %v, got %v and %v", want, task.EndedAt, task.LastNote().EndedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestEdit_AppliesToTaskChangedMeanwhile(t *testing.T) {
+	// arrange
+	m := newEditModel(&fakeTaskSvc{})
+	m.selected = &logItem{task: 1, note: -1}
+	m, cmd := m.handleInput("/e review PR #work")
+	m.addNote("approved")
+
+	// act
+	m = applyCmds(m, cmd)
+
+	// assert
+	got := m.currentTask()
+	if got.Name != "review PR #work" || got.LastNote().Name != "approved" {
+		t.Fatalf("want rename applied with the new note kept, got %+v", got)
+	}
+}
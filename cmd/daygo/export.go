@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportUsage = "usage: daygo /export [--format json|csv|md|ics] [--week|--month|--from YYYY-MM-DD [--to YYYY-MM-DD]] [--tag <tag>]"
+
+const dateFormat = "2006-01-02"
+
+type exportOptions struct {
+	format string
+	// from and to are the first and last days to export
+	from, to time.Time
+	tag      string
+}
+
+func parseExportArgs(args []string, now time.Time) (exportOptions, error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "json", "")
+	days := newRangeFlags(fs)
+	tag := fs.String("tag", "", "")
+	if positional, err := parseArgs(fs, args); err != nil || len(positional) > 0 {
+		return exportOptions{}, fmt.Errorf("%s", exportUsage)
+	}
+
+	from, to, err := days.days(now)
+	if err != nil {
+		return exportOptions{}, err
+	}
+	return exportOptions{
+		format: *format,
+		from:   from,
+		to:     to,
+		tag:    strings.TrimPrefix(*tag, "#"),
+	}, nil
+}
+
+// runExport writes the tasks started within the export's days to w
+func runExport(ctx context.Context, taskSvc TaskSvc, args []string, w io.Writer, timeFormat string) error {
+	opts, err := parseExportArgs(args, time.Now())
+	if err != nil {
+		return err
+	}
+
+	tasks, err := taskSvc.GetTasksByStartTime(ctx, opts.from, opts.to.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return err
+	}
+	if opts.tag != "" {
+		tasks = slices.DeleteFunc(tasks, func(t Task) bool {
+			return !slices.Contains(t.Tags, opts.tag)
+		})
+	}
+
+	switch opts.format {
+	case "json":
+		return exportJSON(w, tasks)
+	case "csv":
+		return exportCSV(w, tasks)
+	case "md":
+		return exportMarkdown(w, tasks, timeFormat)
+	case "ics":
+		return exportICS(w, tasks, timeFormat)
+	default:
+		return fmt.Errorf("unknown format %q: %s", opts.format, exportUsage)
+	}
+}
+
+func exportJSON(w io.Writer, tasks []Task) error {
+	exported := make([]TaskJSON, 0, len(tasks))
+	for _, t := range tasks {
+		exported = append(exported, t.JSON())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exported)
+}
+
+func exportCSV(w io.Writer, tasks []Task) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "name", "tags", "started_at", "ended_at", "duration_minutes", "notes"}); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		var endedAt string
+		if !t.EndedAt.IsZero() {
+			endedAt = t.EndedAt.Format(time.RFC3339)
+		}
+		notes := make([]string, 0, len(t.Notes))
+		for _, n := range t.Notes {
+			notes = append(notes, n.Name)
+		}
+		if err := cw.Write([]string{
+			t.ID.String(),
+			t.Name,
+			strings.Join(t.Tags, " "),
+			t.StartedAt.Format(time.RFC3339),
+			endedAt,
+			strconv.Itoa(int(t.Duration().Round(time.Minute).Minutes())),
+			strings.Join(notes, "\n"),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown renders each day's tasks as they appear in the task log
+func exportMarkdown(w io.Writer, tasks []Task, timeFormat string) error {
+	if len(tasks) == 0 {
+		_, err := fmt.Fprintln(w, "no tasks")
+		return err
+	}
+
+	var sections []string
+	for _, day := range groupByDay(tasks) {
+		var sb strings.Builder
+		var tracked time.Duration
+		fmt.Fprintf(&sb, "## %s\n\n```\n", day[0].StartedAt.Format("Mon Jan 2"))
+		for _, t := range day {
+			t.IsTerminal = !t.EndedAt.IsZero()
+			rendered, _ := t.Render(timeFormat)
+			sb.WriteString(rendered)
+			sb.WriteRune('\n')
+			tracked += t.Duration()
+		}
+		fmt.Fprintf(&sb, "```\n\n%d tasks, %s tracked", len(day), formatDuration(tracked))
+		sections = append(sections, sb.String())
+	}
+	_, err := fmt.Fprintln(w, strings.Join(sections, "\n\n"))
+	return err
+}
+
+// groupByDay splits tasks sorted by start time into the days they started on
+func groupByDay(tasks []Task) [][]Task {
+	var days [][]Task
+	for i, t := range tasks {
+		if i == 0 || !startOfDay(t.StartedAt).Equal(startOfDay(tasks[i-1].StartedAt)) {
+			days = append(days, nil)
+		}
+		days[len(days)-1] = append(days[len(days)-1], t)
+	}
+	return days
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newExportedTasks() []Task {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+
+	first := TaskFromName("write report #work")
+	first.ID = uuid.New()
+	first.CreatedAt = start.Add(-time.Hour)
+	first.UpdatedAt = start.Add(time.Hour)
+	first.QueuedAt = start.Add(-time.Hour)
+	first.StartedAt = start
+	first.EndedAt = start.Add(time.Hour)
+	first.TimeBlockAt = start.Add(45 * time.Minute)
+	first.Pomodoros = 2
+	first.Notes = []Note{{Name: "drafted intro", StartedAt: start.Add(10 * time.Minute), EndedAt: start.Add(time.Hour)}}
+	sub := Subtask{}
+	sub.Name = "outline"
+	sub.EndedAt = start.Add(5 * time.Minute)
+	first.Subtasks = []Subtask{sub}
+	pause := Pause{}
+	pause.StartedAt = start.Add(20 * time.Minute)
+	pause.EndedAt = start.Add(30 * time.Minute)
+	first.Pauses = []Pause{pause}
+
+	second := TaskFromName("finish report #work")
+	second.ID = uuid.New()
+	second.ContinuesID = first.ID
+	second.StartedAt = start.AddDate(0, 0, 1)
+	second.EndedAt = second.StartedAt.Add(30 * time.Minute)
+
+	return []Task{first, second}
+}
+
+func TestExportJSON_RoundTrip(t *testing.T) {
+	// arrange
+	var exported bytes.Buffer
+	if err := exportJSON(&exported, newExportedTasks()); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	imported, err := parseJSONImport(bytes.NewReader(exported.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reexported bytes.Buffer
+	if err := exportJSON(&reexported, imported); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if exported.String() != reexported.String() {
+		t.Fatalf("want %s, got %s", exported.String(), reexported.String())
+	}
+	if imported[0].Duration() != 50*time.Minute {
+		t.Fatalf("want %v, got %v", 50*time.Minute, imported[0].Duration())
+	}
+}
+
+func TestExportMarkdown_GroupsByDay(t *testing.T) {
+	// arrange
+	var buf bytes.Buffer
+
+	// act
+	err := exportMarkdown(&buf, newExportedTasks(), "15:04")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"## Mon Mar 3\n\n```\n[09:00] write report #work",
+		"[09:10] drafted intro",
+		"1 tasks, 50m tracked",
+		"## Tue Mar 4",
+		"1 tasks, 30m tracked",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want %q in %q", want, got)
+		}
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	now := time.Date(2025, 3, 3, 15, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		args     []string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{"defaults to today", nil, "2025-03-03", "2025-03-03", false},
+		{"from only", []string{"--from", "2025-03-01"}, "2025-03-01", "2025-03-03", false},
+		{"month", []string{"--month"}, "2025-03-01", "2025-03-03", false},
+		{"range", []string{"--from=2025-03-01", "--to=2025-03-02", "--tag", "#work"}, "2025-03-01", "2025-03-02", false},
+		{"to before from", []string{"--from", "2025-03-02", "--to", "2025-03-01"}, "", "", true},
+		{"invalid date", []string{"--from", "03/01"}, "", "", true},
+		{"unexpected arg", []string{"today"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			opts, err := parseExportArgs(tt.args, now)
+
+			// assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if got := opts.from.Format(dateFormat); got != tt.wantFrom {
+				t.Fatalf("want %s, got %s", tt.wantFrom, got)
+			}
+			if got := opts.to.Format(dateFormat); got != tt.wantTo {
+				t.Fatalf("want %s, got %s", tt.wantTo, got)
+			}
+		})
+	}
+}
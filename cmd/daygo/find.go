@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	findUsage = "usage: /find <query>"
+	// maxFindResults is how many of the best matching tasks /find shows
+	maxFindResults = 20
+)
+
+// renderFindResults renders tasks found by SearchTasks with their dates and
+// durations
+func renderFindResults(query string, tasks []Task, timeFormat string) string {
+	if len(tasks) == 0 {
+		return fmt.Sprintf("no tasks matching %q", query)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d tasks matching %q", len(tasks), query)
+	for _, t := range tasks {
+		sb.WriteString("\n\n")
+		switch {
+		case t.StartedAt.IsZero():
+			fmt.Fprintf(&sb, "[queued] %s", t.Name)
+			continue
+		case t.EndedAt.IsZero():
+			fmt.Fprintf(&sb, "%s (in progress)\n", t.StartedAt.Format("Mon Jan 2 2006"))
+		default:
+			fmt.Fprintf(&sb, "%s (%s)\n", t.StartedAt.Format("Mon Jan 2 2006"), formatDuration(t.Duration()))
+		}
+		t.IsTerminal = !t.EndedAt.IsZero()
+		rendered, _ := t.Render(timeFormat)
+		sb.WriteString(rendered)
+	}
+	return sb.String()
+}
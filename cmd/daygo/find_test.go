@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFindResults(t *testing.T) {
+	// arrange
+	tasks := newExportedTasks()
+	queued := TaskFromName("report taxes")
+
+	// act
+	got := renderFindResults("report", append(tasks, queued), "15:04")
+
+	// assert
+	for _, want := range []string{
+		`3 tasks matching "report"`,
+		"Mon Mar 3 2025 (50m)\n[09:00] write report #work",
+		"[09:10] drafted intro",
+		"Tue Mar 4 2025 (30m)",
+		"[queued] report taxes",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want %q in %q", want, got)
+		}
+	}
+}
+
+func TestRenderFindResults_NoMatches(t *testing.T) {
+	// act
+	got := renderFindResults("migration", nil, "15:04")
+
+	// assert
+	if want := `no tasks matching "migration"`; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
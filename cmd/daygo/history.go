@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// maxInputHistory is how many entered lines are kept
+const maxInputHistory = 1000
+
+// inputHistory is navigated with up and down like a shell's history
+type inputHistory struct {
+	lines []string
+	// i is the index of the line shown; len(lines) while editing a new line
+	i int
+	// draft is the new line being edited before navigating history
+	draft string
+}
+
+func newInputHistory(lines []string) inputHistory {
+	return inputHistory{
+		lines: lines,
+		i:     len(lines),
+	}
+}
+
+// add appends line unless it repeats the last line and stops navigating.
+// It returns whether line was added.
+func (h *inputHistory) add(line string) bool {
+	defer func() {
+		h.i = len(h.lines)
+		h.draft = ""
+	}()
+	if n := len(h.lines); n > 0 && h.lines[n-1] == line {
+		return false
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > maxInputHistory {
+		h.lines = h.lines[len(h.lines)-maxInputHistory:]
+	}
+	return true
+}
+
+// prev returns the line before the one shown, saving current as the draft if
+// it's new
+func (h *inputHistory) prev(current string) (string, bool) {
+	if h.i == 0 {
+		return "", false
+	}
+	if h.i == len(h.lines) {
+		h.draft = current
+	}
+	h.i--
+	return h.lines[h.i], true
+}
+
+// next returns the line after the one shown, or the draft after the last line
+func (h *inputHistory) next() (string, bool) {
+	if h.i >= len(h.lines) {
+		return "", false
+	}
+	h.i++
+	if h.i == len(h.lines) {
+		return h.draft, true
+	}
+	return h.lines[h.i], true
+}
+
+// LoadInputHistory reads the lines entered in previous sessions, compacting
+// the file to the last maxInputHistory lines. A missing file is empty history.
+func LoadInputHistory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if l := scanner.Text(); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) > maxInputHistory {
+		lines = lines[len(lines)-maxInputHistory:]
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+func appendInputHistory(path, line string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+	return f.Close()
+}
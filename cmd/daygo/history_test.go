@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInputHistory_Navigation(t *testing.T) {
+	// arrange
+	m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{history: []string{"/a review PR", "/find report"}})
+	m = typeInput(m, "draft")
+
+	// act
+	var got []string
+	for _, k := range []tea.KeyType{tea.KeyUp, tea.KeyUp, tea.KeyUp, tea.KeyDown, tea.KeyDown} {
+		m = pressKey(m, k)
+		got = append(got, m.userinput.Value())
+	}
+
+	// assert
+	want := []string{"/find report", "/a review PR", "/a review PR", "/find report", "draft"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestInputHistory_Add(t *testing.T) {
+	// arrange
+	h := newInputHistory([]string{"/k"})
+
+	// act
+	repeated := h.add("/k")
+	added := h.add("/n")
+
+	// assert
+	if repeated || !added {
+		t.Fatalf("want only new line added, got %v and %v", repeated, added)
+	}
+	if want := []string{"/k", "/n"}; !slices.Equal(h.lines, want) {
+		t.Fatalf("want %v, got %v", want, h.lines)
+	}
+}
+
+func TestLoadInputHistory_Persists(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "history")
+	for i := range maxInputHistory + 5 {
+		if err := appendInputHistory(path, fmt.Sprintf("line %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// act
+	lines, err := LoadInputHistory(path)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(lines) != maxInputHistory || lines[0] != "line 5" {
+		t.Fatalf("want last %d lines, got %d starting with %q", maxInputHistory, len(lines), lines[0])
+	}
+	reloaded, err := LoadInputHistory(path)
+	if err != nil || !slices.Equal(reloaded, lines) {
+		t.Fatalf("want compacted history, got %d lines, %v", len(reloaded), err)
+	}
+	if lines, err := LoadInputHistory(filepath.Join(t.TempDir(), "missing")); err != nil || lines != nil {
+		t.Fatalf("want empty history, got %v, %v", lines, err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+type HookEvent string
+
+const (
+	HookOnStart  HookEvent = "on-start"
+	HookOnEnd    HookEvent = "on-end"
+	HookOnQueue  HookEvent = "on-queue"
+	HookOnDelete HookEvent = "on-delete"
+)
+
+// HookRunner runs the executable named after a task lifecycle event in its
+// directory, if there is one, with the task as JSON on stdin. Hooks run in the
+// background; a nil HookRunner runs no hooks.
+type HookRunner struct {
+	dir     string
+	timeout time.Duration
+	l       daygo.Logger
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	onError func(error)
+}
+
+func NewHookRunner(dir string, timeout time.Duration, logger daygo.Logger) *HookRunner {
+	return &HookRunner{
+		dir:     dir,
+		timeout: timeout,
+		l:       logger,
+	}
+}
+
+// OnError sets the handler for hook failures. If nil, failures are printed to stderr.
+func (h *HookRunner) OnError(f func(error)) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = f
+}
+
+func (h *HookRunner) Run(event HookEvent, t Task) {
+	if h == nil {
+		return
+	}
+
+	path := filepath.Join(h.dir, string(event))
+	if _, err := os.Stat(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			h.fail(event, err)
+		}
+		return
+	}
+	data, err := json.Marshal(t.JSON())
+	if err != nil {
+		h.fail(event, err)
+		return
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.l.Debug("running hook", "event", event, "task", t.Name)
+		if err := h.run(path, event, t, data); err != nil {
+			h.fail(event, err)
+		}
+	}()
+}
+
+// Wait blocks until running hooks complete
+func (h *HookRunner) Wait() {
+	if h == nil {
+		return
+	}
+	h.wg.Wait()
+}
+
+func (h *HookRunner) run(path string, event HookEvent, t Task, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, path)
+	// don't wait on output held open by processes the hook left behind
+	c.WaitDelay = time.Second
+	c.Stdin = bytes.NewReader(data)
+	c.Env = append(
+		os.Environ(),
+		"DAYGO_HOOK="+string(event),
+		"DAYGO_TASK_ID="+t.ID.String(),
+		"DAYGO_TASK_NAME="+t.Name,
+		"DAYGO_TASK_TAGS="+strings.Join(t.Tags, ","),
+	)
+	out, err := c.CombinedOutput()
+	if ctx.Err() != nil {
+		return fmt.Errorf("timed out after %s", h.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (h *HookRunner) fail(event HookEvent, err error) {
+	err = fmt.Errorf("%s hook failed: %w", event, err)
+	h.l.Error(err)
+
+	h.mu.Lock()
+	onError := h.onError
+	h.mu.Unlock()
+	if onError != nil {
+		onError(err)
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+func writeHook(t *testing.T, dir string, event HookEvent, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, string(event)), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHookRunner(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	writeHook(t, dir, HookOnStart, `cat > "`+out+`"; echo "$DAYGO_HOOK $DAYGO_TASK_TAGS" >> "`+out+`.env"`)
+	h := NewHookRunner(dir, time.Second, daygo.NoOpLogger{})
+	var errs []error
+	h.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+	task := TaskFromName("deploy #work #ops")
+
+	// act
+	h.Run(HookOnStart, task)
+	// no hook for this event
+	h.Run(HookOnEnd, task)
+	h.Wait()
+
+	// assert
+	if len(errs) > 0 {
+		t.Fatalf("want no errors, got %v", errs)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TaskJSON
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != task.Name {
+		t.Fatalf("want %q, got %q", task.Name, got.Name)
+	}
+	env, err := os.ReadFile(out + ".env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "on-start work,ops"; strings.TrimSpace(string(env)) != want {
+		t.Fatalf("want %q, got %q", want, env)
+	}
+}
+
+func TestHookRunnerFailure(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	writeHook(t, dir, HookOnDelete, "echo nope >&2; exit 3")
+	writeHook(t, dir, HookOnQueue, "exec sleep 5")
+	h := NewHookRunner(dir, 100*time.Millisecond, daygo.NoOpLogger{})
+	errs := make(chan error, 2)
+	h.OnError(func(err error) {
+		errs <- err
+	})
+
+	// act
+	h.Run(HookOnDelete, TaskFromName("a"))
+	h.Run(HookOnQueue, TaskFromName("b"))
+	h.Wait()
+	close(errs)
+
+	// assert
+	var msgs []string
+	for err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	joined := strings.Join(msgs, "\n")
+	if len(msgs) != 2 || !strings.Contains(joined, "on-delete hook failed") || !strings.Contains(joined, "nope") || !strings.Contains(joined, "timed out") {
+		t.Fatalf("want on-delete failure and on-queue timeout, got %q", joined)
+	}
+}
+
+func TestNilHookRunner(t *testing.T) {
+	var h *HookRunner
+	h.Run(HookOnStart, TaskFromName("a"))
+	h.OnError(nil)
+	h.Wait()
+}
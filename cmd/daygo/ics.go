@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// exportICS writes each ended task as an iCalendar event. Events are identified
+// by task ID so that calendars update rather than duplicate re-exported tasks.
+func exportICS(w io.Writer, tasks []Task, timeFormat string) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//daygo//daygo//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	for _, t := range tasks {
+		if t.EndedAt.IsZero() {
+			continue
+		}
+		stamp := t.UpdatedAt
+		if stamp.IsZero() {
+			stamp = t.EndedAt
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s@daygo", t.ID),
+			"DTSTAMP:"+stamp.UTC().Format(icsTimeFormat),
+			"DTSTART:"+t.StartedAt.UTC().Format(icsTimeFormat),
+			"DTEND:"+t.EndedAt.UTC().Format(icsTimeFormat),
+			"SUMMARY:"+escapeICSText(withoutTags(t.Name)),
+		)
+		if len(t.Notes) > 0 {
+			notes := make([]string, 0, len(t.Notes))
+			for _, n := range t.Notes {
+				notes = append(notes, n.Render(timeFormat))
+			}
+			lines = append(lines, "DESCRIPTION:"+escapeICSText(strings.Join(notes, "\n")))
+		}
+		if len(t.Tags) > 0 {
+			tags := make([]string, 0, len(t.Tags))
+			for _, tag := range t.Tags {
+				tags = append(tags, escapeICSText(tag))
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(foldICSLine(l))
+		sb.WriteString("\r\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// withoutTags removes #tags from the task name unless it is only tags
+func withoutTags(name string) string {
+	var words []string
+	for w := range strings.SplitSeq(name, " ") {
+		if !strings.HasPrefix(w, "#") {
+			words = append(words, w)
+		}
+	}
+	if stripped := strings.TrimSpace(strings.Join(words, " ")); stripped != "" {
+		return stripped
+	}
+	return name
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing them on lines
+// starting with a space, without splitting UTF-8 characters
+func foldICSLine(l string) string {
+	const maxLen = 75
+	var sb strings.Builder
+	n := 0
+	for _, r := range l {
+		size := len(string(r))
+		if n+size > maxLen {
+			sb.WriteString("\r\n ")
+			n = 1
+		}
+		sb.WriteRune(r)
+		n += size
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExportICS(t *testing.T) {
+	// arrange
+	tasks := newExportedTasks()
+	tasks[0].Notes[0].Name = "drafted intro; waiting on data, figures"
+	inProgress := TaskFromName("in progress #work")
+	inProgress.StartedAt = tasks[1].EndedAt
+	tasks = append(tasks, inProgress)
+	var buf bytes.Buffer
+
+	// act
+	err := exportICS(&buf, tasks, "15:04")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	got := buf.String()
+	if n := strings.Count(got, "BEGIN:VEVENT"); n != 2 {
+		t.Fatalf("want 2 events, got %d", n)
+	}
+	for _, want := range []string{
+		"UID:" + tasks[0].ID.String() + "@daygo\r\n",
+		"DTSTART:" + tasks[0].StartedAt.UTC().Format(icsTimeFormat) + "\r\n",
+		"SUMMARY:write report\r\n",
+		`DESCRIPTION:[09:10] drafted intro\; waiting on data\, figures` + "\r\n",
+		"CATEGORIES:work\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "in progress") {
+		t.Fatalf("want in progress task skipped, got %q", got)
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	// arrange
+	l := "DESCRIPTION:" + strings.Repeat("é", 80)
+
+	// act
+	folded := foldICSLine(l)
+
+	// assert
+	lines := strings.Split(folded, "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("want folded line, got %q", folded)
+	}
+	var unfolded string
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Fatalf("want at most 75 octets, got %d", len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Fatalf("want valid UTF-8, got %q", line)
+		}
+		if i > 0 {
+			line = strings.TrimPrefix(line, " ")
+		}
+		unfolded += line
+	}
+	if unfolded != l {
+		t.Fatalf("want %q, got %q", l, unfolded)
+	}
+}
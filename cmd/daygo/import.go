@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const importUsage = "usage: daygo /import <file> [--format json|todotxt|taskwarrior-json|lines] [--completed skip|history]"
+
+// importResult summarizes an import
+type importResult struct {
+	imported int
+	// history is the number of imported tasks that were already completed
+	history int
+	// completed is the number of completed tasks skipped
+	completed int
+	// duplicates are the names of tasks skipped because they're already queued or imported
+	duplicates []string
+}
+
+func (r importResult) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Imported %d tasks", r.imported)
+	if r.history > 0 {
+		fmt.Fprintf(&sb, " (%d as history)", r.history)
+	}
+	if r.completed > 0 {
+		fmt.Fprintf(&sb, "\nSkipped %d completed tasks", r.completed)
+	}
+	if len(r.duplicates) > 0 {
+		fmt.Fprintf(&sb, "\nSkipped %d duplicates:", len(r.duplicates))
+		for _, name := range r.duplicates {
+			fmt.Fprintf(&sb, "\n  %s", name)
+		}
+	}
+	return sb.String()
+}
+
+func runImport(ctx context.Context, taskSvc TaskSvc, args []string) (importResult, error) {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "json", "")
+	completed := fs.String("completed", "skip", "")
+	positional, err := parseArgs(fs, args)
+	if err != nil || len(positional) != 1 {
+		return importResult{}, fmt.Errorf("%s", importUsage)
+	}
+	if *completed != "skip" && *completed != "history" {
+		return importResult{}, fmt.Errorf("%s", importUsage)
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return importResult{}, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	now := time.Now()
+	var tasks []Task
+	switch *format {
+	case "json":
+		tasks, err = parseJSONImport(f)
+		if err != nil {
+			return importResult{}, fmt.Errorf("failed to parse %s: %w", positional[0], err)
+		}
+		return importExported(ctx, taskSvc, tasks)
+	case "todotxt":
+		tasks, err = parseTodoTxt(f, now)
+	case "taskwarrior-json":
+		tasks, err = parseTaskwarriorJSON(f)
+	case "lines":
+		tasks, err = parseLines(f)
+	default:
+		return importResult{}, fmt.Errorf("unknown format %q: %s", *format, importUsage)
+	}
+	if err != nil {
+		return importResult{}, fmt.Errorf("failed to parse %s: %w", positional[0], err)
+	}
+	return importTasks(ctx, taskSvc, tasks, *completed == "history", now)
+}
+
+// importExported imports tasks written by /export, keeping their IDs
+func importExported(ctx context.Context, taskSvc TaskSvc, tasks []Task) (importResult, error) {
+	var res importResult
+	for _, t := range tasks {
+		if _, err := taskSvc.ImportTask(ctx, t); err != nil {
+			if errors.Is(err, ErrTaskExists) {
+				res.duplicates = append(res.duplicates, t.Name)
+				continue
+			}
+			return res, fmt.Errorf("failed to import %q: %w", t.Name, err)
+		}
+		res.imported++
+		if !t.EndedAt.IsZero() {
+			res.history++
+		}
+	}
+	return res, nil
+}
+
+// importTasks queues tasks that haven't ended in the order given, skipping
+// tasks already queued under the same name. Ended tasks are imported as history
+// if withHistory.
+func importTasks(ctx context.Context, taskSvc TaskSvc, tasks []Task, withHistory bool, now time.Time) (importResult, error) {
+	pending, err := taskSvc.GetPendingTasks(ctx)
+	if err != nil {
+		return importResult{}, err
+	}
+	queued := make(map[string]bool, len(pending))
+	for _, t := range pending {
+		queued[t.Name] = true
+	}
+
+	var res importResult
+	for i, t := range tasks {
+		if !t.EndedAt.IsZero() {
+			if !withHistory {
+				res.completed++
+				continue
+			}
+			if _, err := taskSvc.ImportTask(ctx, t); err != nil {
+				return res, fmt.Errorf("failed to import %q: %w", t.Name, err)
+			}
+			res.imported++
+			res.history++
+			continue
+		}
+
+		if queued[t.Name] {
+			res.duplicates = append(res.duplicates, t.Name)
+			continue
+		}
+		if t.QueuedAt.IsZero() {
+			// queue undated tasks in order, before any queued from now on
+			t.QueuedAt = now.Add(time.Duration(i-len(tasks)) * time.Second)
+		}
+		if _, err := taskSvc.QueueTask(ctx, t); err != nil {
+			return res, fmt.Errorf("failed to queue %q: %w", t.Name, err)
+		}
+		queued[t.Name] = true
+		res.imported++
+	}
+	return res, nil
+}
+
+// parseJSONImport reads tasks written by /export --format json
+func parseJSONImport(r io.Reader) ([]Task, error) {
+	var exported []TaskJSON
+	if err := json.NewDecoder(r).Decode(&exported); err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, 0, len(exported))
+	for _, j := range exported {
+		if j.Name == "" {
+			return nil, fmt.Errorf("task without name")
+		}
+		tasks = append(tasks, TaskFromJSON(j))
+	}
+	return tasks, nil
+}
+
+var (
+	todoTxtDateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtPriorityRe = regexp.MustCompile(`^\([A-Z]\)$`)
+)
+
+// parseTodoTxt reads tasks in the todo.txt format, converting +projects and
+// @contexts into tags. Completed tasks are ended on their completion date.
+func parseTodoTxt(r io.Reader, now time.Time) ([]Task, error) {
+	parseDate := func(s string) (time.Time, bool) {
+		if !todoTxtDateRe.MatchString(s) {
+			return time.Time{}, false
+		}
+		d, err := time.ParseInLocation(dateFormat, s, now.Location())
+		return d, err == nil
+	}
+
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	for n := 0; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var completed bool
+		var completedAt, createdAt time.Time
+		if fields[0] == "x" {
+			completed = true
+			fields = fields[1:]
+			if len(fields) > 0 {
+				if d, ok := parseDate(fields[0]); ok {
+					completedAt = d
+					fields = fields[1:]
+				}
+			}
+		}
+		if len(fields) > 0 && todoTxtPriorityRe.MatchString(fields[0]) {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			if d, ok := parseDate(fields[0]); ok {
+				createdAt = d
+				fields = fields[1:]
+			}
+		}
+
+		words := make([]string, 0, len(fields))
+		for _, f := range fields {
+			if len(f) > 1 && (f[0] == '+' || f[0] == '@') {
+				f = "#" + f[1:]
+			}
+			words = append(words, f)
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		t := TaskFromName(strings.Join(words, " "))
+		if !createdAt.IsZero() {
+			// keep the file's order among tasks created on the same day
+			t.QueuedAt = createdAt.Add(time.Duration(n) * time.Second)
+		}
+		if completed {
+			end := cmp.Or(completedAt, createdAt, now)
+			t.StartedAt = end
+			t.EndedAt = end
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, scanner.Err()
+}
+
+// taskwarriorTask is a task as written by `task export`
+type taskwarriorTask struct {
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Entry       string   `json:"entry"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+}
+
+const taskwarriorTimeFormat = "20060102T150405Z"
+
+// parseTaskwarriorJSON reads tasks written by `task export`, either as an
+// array or one object per line. Deleted and recurring template tasks are
+// skipped, and the project and tags are converted into tags.
+func parseTaskwarriorJSON(r io.Reader) ([]Task, error) {
+	br := bufio.NewReader(r)
+	var exported []taskwarriorTask
+	dec := json.NewDecoder(br)
+	if b, err := peekNonSpace(br); errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if b == '[' {
+		if err := dec.Decode(&exported); err != nil {
+			return nil, err
+		}
+	} else {
+		for {
+			var tw taskwarriorTask
+			if err := dec.Decode(&tw); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			exported = append(exported, tw)
+		}
+	}
+
+	parseTime := func(s string) (time.Time, error) {
+		if s == "" {
+			return time.Time{}, nil
+		}
+		t, err := time.Parse(taskwarriorTimeFormat, s)
+		return t.Local(), err
+	}
+
+	var tasks []Task
+	for _, tw := range exported {
+		if tw.Status == "deleted" || tw.Status == "recurring" || tw.Description == "" {
+			continue
+		}
+
+		tags := slices.Clone(tw.Tags)
+		if tw.Project != "" {
+			tags = append([]string{tw.Project}, tags...)
+		}
+		for i, tag := range tags {
+			tags[i] = strings.ReplaceAll(tag, " ", "_")
+		}
+		t := TaskFromName(withTags(tw.Description, tags))
+
+		var err error
+		if t.QueuedAt, err = parseTime(tw.Entry); err != nil {
+			return nil, fmt.Errorf("invalid entry %q: %w", tw.Entry, err)
+		}
+		if tw.Status == "completed" {
+			start, err := parseTime(tw.Start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start %q: %w", tw.Start, err)
+			}
+			end, err := parseTime(tw.End)
+			if err != nil {
+				return nil, fmt.Errorf("invalid end %q: %w", tw.End, err)
+			}
+			end = cmp.Or(end, t.QueuedAt, time.Now())
+			t.StartedAt = cmp.Or(start, end)
+			t.EndedAt = end
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// parseLines reads one task per line, ignoring blank lines and list bullets
+func parseLines(r io.Reader) ([]Task, error) {
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		for _, bullet := range []string{"- ", "* "} {
+			name = strings.TrimSpace(strings.TrimPrefix(name, bullet))
+		}
+		if name == "" {
+			continue
+		}
+		tasks = append(tasks, TaskFromName(name))
+	}
+	return tasks, scanner.Err()
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			return b[0], nil
+		}
+		if _, err := br.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTodoTxt(t *testing.T) {
+	// arrange
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+	in := `(A) 2025-03-01 call mom +family @phone
+2025-03-01 water plants @home
+
+x 2025-03-02 2025-02-28 file taxes +admin
+buy milk`
+
+	// act
+	tasks, err := parseTodoTxt(strings.NewReader(in), now)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	wantNames := []string{"call mom #family #phone", "water plants #home", "file taxes #admin", "buy milk"}
+	if !slices.Equal(names, wantNames) {
+		t.Fatalf("want %v, got %v", wantNames, names)
+	}
+	if !slices.Equal(tasks[0].Tags, []string{"family", "phone"}) {
+		t.Fatalf("want %v, got %v", []string{"family", "phone"}, tasks[0].Tags)
+	}
+	if !tasks[0].QueuedAt.Before(tasks[1].QueuedAt) {
+		t.Fatalf("want %v before %v", tasks[0].QueuedAt, tasks[1].QueuedAt)
+	}
+	if want := time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local); !tasks[2].EndedAt.Equal(want) {
+		t.Fatalf("want %v, got %v", want, tasks[2].EndedAt)
+	}
+	if !tasks[3].QueuedAt.IsZero() || !tasks[3].EndedAt.IsZero() {
+		t.Fatalf("want undated pending task, got %+v", tasks[3])
+	}
+}
+
+func TestParseTaskwarriorJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"array", `[
+{"description":"review PR","status":"pending","project":"work","tags":["code"],"entry":"20250301T120000Z"},
+{"description":"old chore","status":"deleted","entry":"20250301T120000Z"},
+{"description":"deploy","status":"completed","entry":"20250301T120000Z","start":"20250302T090000Z","end":"20250302T100000Z"}
+]`},
+		{"lines", `{"description":"review PR","status":"pending","project":"work","tags":["code"],"entry":"20250301T120000Z"}
+{"description":"old chore","status":"deleted","entry":"20250301T120000Z"}
+{"description":"deploy","status":"completed","entry":"20250301T120000Z","start":"20250302T090000Z","end":"20250302T100000Z"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			tasks, err := parseTaskwarriorJSON(strings.NewReader(tt.in))
+
+			// assert
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if len(tasks) != 2 {
+				t.Fatalf("want 2 tasks, got %d", len(tasks))
+			}
+			if tasks[0].Name != "review PR #work #code" {
+				t.Fatalf("want %q, got %q", "review PR #work #code", tasks[0].Name)
+			}
+			if want := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC); !tasks[0].QueuedAt.Equal(want) {
+				t.Fatalf("want %v, got %v", want, tasks[0].QueuedAt)
+			}
+			if got := tasks[1].EndedAt.Sub(tasks[1].StartedAt); got != time.Hour {
+				t.Fatalf("want %v, got %v", time.Hour, got)
+			}
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	// act
+	tasks, err := parseLines(strings.NewReader("- write report #work\n\n* review PR\nwater plants\n"))
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	if want := []string{"write report #work", "review PR", "water plants"}; !slices.Equal(names, want) {
+		t.Fatalf("want %v, got %v", want, names)
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	// arrange
+	now := time.Now()
+	svc := &fakeTaskSvc{pending: []Task{newPersistedTask("review PR")}}
+	done := TaskFromName("file taxes")
+	done.StartedAt = now.Add(-time.Hour)
+	done.EndedAt = done.StartedAt
+	tasks := []Task{
+		TaskFromName("write report"),
+		TaskFromName("review PR"),
+		done,
+		TaskFromName("water plants"),
+		TaskFromName("write report"),
+	}
+
+	// act
+	res, err := importTasks(context.Background(), svc, tasks, false, now)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if res.imported != 2 || res.completed != 1 {
+		t.Fatalf("want 2 imported and 1 completed, got %+v", res)
+	}
+	if want := []string{"review PR", "write report"}; !slices.Equal(res.duplicates, want) {
+		t.Fatalf("want %v, got %v", want, res.duplicates)
+	}
+	queued := svc.pending[1:]
+	if queued[0].Name != "write report" || queued[1].Name != "water plants" {
+		t.Fatalf("want write report then water plants, got %+v", queued)
+	}
+	if !queued[0].QueuedAt.Before(queued[1].QueuedAt) || !queued[1].QueuedAt.Before(now) {
+		t.Fatalf("want queued in order before %v, got %v and %v", now, queued[0].QueuedAt, queued[1].QueuedAt)
+	}
+}
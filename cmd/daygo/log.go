@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	logUsage     = "usage: /log [YYYY-MM-DD] <HHMM>-<HHMM> <task>[; [HHMM] <note>...]"
+	startAtUsage = "usage: /n@<HHMM> [task]"
+)
+
+var (
+	logRangeRe = regexp.MustCompile(`^((?:[01]\d|2[0-3])[0-5]\d)-((?:[01]\d|2[0-3])[0-5]\d)$`)
+	noteTimeRe = regexp.MustCompile(`^((?:[01]\d|2[0-3])[0-5]\d)\s+(.+)$`)
+)
+
+// parseLoggedTask parses "[YYYY-MM-DD] <HHMM>-<HHMM> <task>[; [HHMM] <note>...]"
+// into a task ended on that day, today by default. Notes without a time start
+// with the note before them, or the task.
+func parseLoggedTask(arg string, now time.Time) (Task, error) {
+	fields := strings.Fields(arg)
+	day := now
+	if len(fields) > 0 {
+		if d, err := time.ParseInLocation(dateFormat, fields[0], now.Location()); err == nil {
+			day = d
+			fields = fields[1:]
+		}
+	}
+	if len(fields) < 2 {
+		return Task{}, fmt.Errorf("%s", logUsage)
+	}
+	m := logRangeRe.FindStringSubmatch(fields[0])
+	if m == nil {
+		return Task{}, fmt.Errorf("invalid time range %q: %s", fields[0], logUsage)
+	}
+
+	items := strings.Split(strings.Join(fields[1:], " "), ";")
+	name := strings.TrimSpace(items[0])
+	if name == "" {
+		return Task{}, fmt.Errorf("%s", logUsage)
+	}
+	t := TaskFromName(name)
+	t.StartedAt = timeOfDay(day, m[1])
+	t.EndedAt = timeOfDay(day, m[2])
+	if t.EndedAt.After(now) {
+		return Task{}, fmt.Errorf("\"%s\" can't end in the future", name)
+	}
+
+	at := t.StartedAt
+	for _, item := range items[1:] {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if nm := noteTimeRe.FindStringSubmatch(item); nm != nil {
+			at = timeOfDay(day, nm[1])
+			item = nm[2]
+		}
+		if n := t.LastNote(); n != nil {
+			n.EndedAt = at
+		}
+		t.Notes = append(t.Notes, Note{
+			Name:      item,
+			StartedAt: at,
+		})
+	}
+	if n := t.LastNote(); n != nil {
+		n.EndedAt = t.EndedAt
+	}
+	return t, nil
+}
+
+// logTask persists a task done earlier and adds it to the task log; returns
+// error if arg can't be parsed
+func (m model) logTask(arg string) (tea.Cmd, error) {
+	t, err := parseLoggedTask(arg, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return func() tea.Msg {
+		timeout, c := m.newTimeout()
+		defer c()
+		logged, err := m.taskSvc.LogTask(timeout, t)
+		if err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		return TaskLoggedMsg{
+			task: logged,
+		}
+	}, nil
+}
+
+// insertLoggedTask adds the task to the task log in order of start if it was
+// done today
+func (m *model) insertLoggedTask(t Task) {
+	if t.StartedAt.Format(dateFormat) != time.Now().Format(dateFormat) {
+		return
+	}
+	i := len(m.taskLog)
+	for i > 0 && m.taskLog[i-1].StartedAt.After(t.StartedAt) {
+		i--
+	}
+	m.taskLog = append(m.taskLog[:i], append([]Task{t}, m.taskLog[i:]...)...)
+	m.selected = nil
+}
+
+// startAsOf ends the pending task and starts a new task, or dequeues one if
+// name is empty, as of an earlier time today. The time must be after the
+// pending task's last note and pause or, if there's no pending task, the last
+// ended task.
+func (m *model) startAsOf(name, hhmm string) (tea.Cmd, error) {
+	now := time.Now()
+	if !timeRe.MatchString(hhmm) {
+		return nil, fmt.Errorf("%s", startAtUsage)
+	}
+	at := timeOfDay(now, hhmm)
+	if at.After(now) {
+		return nil, fmt.Errorf("%s is in the future", at.Format(m.opts.timeFormat))
+	}
+	if name == "" && m.taskQueue.Size() == 0 {
+		return nil, fmt.Errorf("task queue is empty")
+	}
+
+	var before Task
+	if t := m.currentTask(); t.IsPending() {
+		if !at.After(t.StartedAt) {
+			return nil, fmt.Errorf("\"%s\" started at %s", t.Name, t.StartedAt.Format(m.opts.timeFormat))
+		}
+		if n := t.LastNote(); n != nil && n.StartedAt.After(at) {
+			return nil, fmt.Errorf("note \"%s\" was added at %s", n.Name, n.StartedAt.Format(m.opts.timeFormat))
+		}
+		if len(t.Pauses) > 0 && t.Pauses[len(t.Pauses)-1].StartedAt.After(at) {
+			return nil, fmt.Errorf("\"%s\" was paused at %s", t.Name, t.Pauses[len(t.Pauses)-1].StartedAt.Format(m.opts.timeFormat))
+		}
+		before = cloneTask(*t)
+	} else if last := m.lastEndedTask(); at.Before(last.EndedAt.Truncate(time.Second)) {
+		return nil, fmt.Errorf("\"%s\" ended at %s", last.Name, last.EndedAt.Format(m.opts.timeFormat))
+	}
+
+	next := TaskFromName(name)
+	if name == "" {
+		next = m.taskQueue.Dequeue()
+	}
+	var persistEnded tea.Cmd
+	ended, err := m.endPendingTaskAt(at)
+	if err == nil {
+		persistEnded = m.persistEndedTask(ended)
+	}
+	startCmd := m.startTaskAt(next, at)
+	if err == nil {
+		m.recordUndo(undoEnd, before, ended.EndedAt)
+	}
+	return tea.Batch(persistEnded, startCmd), nil
+}
+
+// lastEndedTask returns the task that ended last in the task log or, if none
+// ended later, before this run
+func (m model) lastEndedTask() Task {
+	last := m.lastEnded
+	for _, t := range m.taskLog {
+		if t.EndedAt.After(last.EndedAt) {
+			last = t
+		}
+	}
+	return last
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLoggedTask(t *testing.T) {
+	now := time.Date(2025, 3, 10, 18, 0, 0, 0, time.Local)
+	at := func(day, hhmm string) time.Time {
+		d, _ := time.ParseInLocation(dateFormat, day, time.Local)
+		return timeOfDay(d, hhmm)
+	}
+	tests := []struct {
+		name      string
+		arg       string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantNotes []Note
+		wantErr   bool
+	}{
+		{
+			name:      "today",
+			arg:       "0930-1045 standup #work",
+			wantStart: at("2025-03-10", "0930"),
+			wantEnd:   at("2025-03-10", "1045"),
+		},
+		{
+			name:      "earlier day",
+			arg:       "2025-03-07 2200-2330 read",
+			wantStart: at("2025-03-07", "2200"),
+			wantEnd:   at("2025-03-07", "2330"),
+		},
+		{
+			name:      "notes",
+			arg:       "0930-1045 standup; agenda; 1000 demo",
+			wantStart: at("2025-03-10", "0930"),
+			wantEnd:   at("2025-03-10", "1045"),
+			wantNotes: []Note{
+				{Name: "agenda", StartedAt: at("2025-03-10", "0930"), EndedAt: at("2025-03-10", "1000")},
+				{Name: "demo", StartedAt: at("2025-03-10", "1000"), EndedAt: at("2025-03-10", "1045")},
+			},
+		},
+		{name: "future", arg: "1730-1830 standup", wantErr: true},
+		{name: "missing task", arg: "0930-1045", wantErr: true},
+		{name: "invalid range", arg: "0930 standup", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := parseLoggedTask(tt.arg, now)
+
+			// assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if !got.StartedAt.Equal(tt.wantStart) || !got.EndedAt.Equal(tt.wantEnd) {
+				t.Fatalf("want %v-%v, got %v-%v", tt.wantStart, tt.wantEnd, got.StartedAt, got.EndedAt)
+			}
+			if len(got.Notes) != len(tt.wantNotes) {
+				t.Fatalf("want %+v, got %+v", tt.wantNotes, got.Notes)
+			}
+			for i, n := range tt.wantNotes {
+				g := got.Notes[i]
+				if g.Name != n.Name || !g.StartedAt.Equal(n.StartedAt) || !g.EndedAt.Equal(n.EndedAt) {
+					t.Fatalf("want %+v, got %+v", tt.wantNotes, got.Notes)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertLoggedTask(t *testing.T) {
+	// arrange
+	m, curr, _ := newUndoModel(&fakeTaskSvc{})
+	logged := TaskFromName("standup")
+	logged.StartedAt = curr.StartedAt.Add(-time.Hour)
+	logged.EndedAt = curr.StartedAt.Add(-30 * time.Minute)
+
+	// act
+	m.insertLoggedTask(logged)
+
+	// assert
+	if len(m.taskLog) != 2 || m.taskLog[0].Name != "standup" || m.currentTask().ID != curr.ID {
+		t.Fatalf("want %q logged before %q, got %+v", "standup", curr.Name, m.taskLog)
+	}
+}
+
+func TestStartAsOf(t *testing.T) {
+	// arrange
+	now := time.Now()
+	at := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, now.Location())
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+	m.taskLog[0].StartedAt = at.Add(-time.Hour)
+
+	// act
+	m, cmd := m.handleInput("/n@" + at.Format("1504") + " draft slides")
+	runCmds(cmd)
+
+	// assert
+	if len(m.taskLog) != 2 || !m.taskLog[0].EndedAt.Equal(at) {
+		t.Fatalf("want %q ended at %v, got %+v", curr.Name, at, m.taskLog)
+	}
+	if got := m.currentTask(); got.Name != "draft slides" || !got.StartedAt.Equal(at) {
+		t.Fatalf("want %q started at %v, got %+v", "draft slides", at, got)
+	}
+	if len(m.undoLog) != 1 || m.undoLog[0].kind != undoEnd {
+		t.Fatalf("want end recorded for undo, got %+v", m.undoLog)
+	}
+}
+
+func TestStartAsOf_RejectsBeforeCurrentTask(t *testing.T) {
+	// arrange
+	m, curr, next := newUndoModel(&fakeTaskSvc{})
+	hhmm := curr.StartedAt.Add(-time.Minute).Format("1504")
+
+	// act
+	m, _ = m.handleInput("/n@" + hhmm)
+
+	// assert
+	if got := m.currentTask(); got.ID != curr.ID || !got.IsPending() {
+		t.Fatalf("want %q still pending, got %+v", curr.Name, got)
+	}
+	if m.taskQueue.Size() != 1 || m.taskQueue.Peek().ID != next.ID {
+		t.Fatalf("want %q still queued, got %+v", next.Name, m.taskQueue.Peek())
+	}
+	if len(m.alerts) != 1 {
+		t.Fatalf("want error alert, got %v", m.alerts)
+	}
+}
+
+func TestStartAsOf_EmptyQueue(t *testing.T) {
+	// arrange
+	m, curr, _ := newUndoModel(&fakeTaskSvc{})
+	m.taskQueue = NewTaskQueue(nil)
+	hhmm := time.Now().Format("1504")
+
+	// act
+	m, _ = m.handleInput("/n@" + hhmm + " ")
+
+	// assert
+	if got := m.currentTask(); got.ID != curr.ID || !got.IsPending() {
+		t.Fatalf("want %q still pending, got %+v", curr.Name, got)
+	}
+	if len(m.alerts) != 1 {
+		t.Fatalf("want error alert, got %v", m.alerts)
+	}
+}
+
+func TestLog_InvalidInput(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+
+	// act
+	m, cmd := m.handleInput("/log 0930 standup")
+
+	// assert
+	if cmd != nil {
+		t.Fatalf("want nil cmd, got %v", cmd)
+	}
+	if len(m.alerts) != 1 || !strings.Contains(m.alerts[0], logUsage) {
+		t.Fatalf("want usage alert, got %v", m.alerts)
+	}
+}
+
+func TestStartAsOf_RejectsBeforeLastEnded(t *testing.T) {
+	// arrange
+	now := time.Now()
+	m := NewModel(&fakeTaskSvc{}, nil, nil, modelOptions{})
+	m.taskQueue = NewTaskQueue(nil)
+	m.lastEnded = newPersistedTask("write report")
+	m.lastEnded.StartedAt = now.Add(-time.Hour)
+	m.lastEnded.EndedAt = now
+	hhmm := now.Add(-time.Minute).Format("1504")
+
+	// act
+	m, _ = m.handleInput("/n@" + hhmm + " review PR")
+
+	// assert
+	if len(m.taskLog) != 0 {
+		t.Fatalf("want nothing started, got %+v", m.taskLog)
+	}
+	if len(m.alerts) != 1 {
+		t.Fatalf("want error alert, got %v", m.alerts)
+	}
+}
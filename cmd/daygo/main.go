@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +27,13 @@ var migrations embed.FS
 var logger daygo.Logger
 
 func main() {
+	// flags
+	noHooks := slices.Contains(os.Args[1:], "--no-hooks")
+	jsonOutput := slices.Contains(os.Args[1:], "--json")
+	os.Args = slices.DeleteFunc(os.Args, func(arg string) bool {
+		return arg == "--no-hooks" || arg == "--json"
+	})
+
 	// cfg
 	cfgDir, _ := os.UserConfigDir()
 	cfgPath := path.Join(cfgDir, "daygo", "daygo.conf")
@@ -35,7 +44,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	var logPath, logLvl, dbURL, timeFormat, syncServerURL, syncRate, cmdTimeout string
+	var logPath, logLvl, dbURL, timeFormat, syncServerURL, syncRate, cmdTimeout, queueMode, requeueSubtasks, idleTimeout, pomoBreakAction, timeBlockWarn, notify, notifyCmd, hooksDir, socketPath, statusFormat, timesheetRound, timesheetProjects, historyPath string
 	if err := cfg.GetMany([]config.Key{
 		KeyLogPath,
 		KeyLogLevel,
@@ -44,7 +53,20 @@ func main() {
 		KeySyncServerURL,
 		KeySyncRate,
 		KeyCmdTimeout,
-	}, &logPath, &logLvl, &dbURL, &timeFormat, &syncServerURL, &syncRate, &cmdTimeout); err != nil {
+		KeyQueueMode,
+		KeyRequeueSubtasks,
+		KeyIdleTimeout,
+		KeyPomoBreakAction,
+		KeyTimeBlockWarn,
+		KeyNotify,
+		KeyNotifyCmd,
+		KeyHooksDir,
+		KeySocketPath,
+		KeyStatusFormat,
+		KeyTimesheetRound,
+		KeyTimesheetProjects,
+		KeyHistoryPath,
+	}, &logPath, &logLvl, &dbURL, &timeFormat, &syncServerURL, &syncRate, &cmdTimeout, &queueMode, &requeueSubtasks, &idleTimeout, &pomoBreakAction, &timeBlockWarn, &notify, &notifyCmd, &hooksDir, &socketPath, &statusFormat, &timesheetRound, &timesheetProjects, &historyPath); err != nil {
 		panic(err)
 	}
 	sr, err := time.ParseDuration(syncRate)
@@ -55,6 +77,37 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	queueStrategy, err := ParseDequeueStrategy(queueMode)
+	if err != nil {
+		panic(err)
+	}
+	shouldRequeueSubtasks, err := strconv.ParseBool(requeueSubtasks)
+	if err != nil {
+		panic(err)
+	}
+	idleTo, err := time.ParseDuration(idleTimeout)
+	if err != nil {
+		panic(err)
+	}
+	breakAction, err := ParsePomoBreakAction(pomoBreakAction)
+	if err != nil {
+		panic(err)
+	}
+	timeBlockWarnings, err := ParseTimeBlockWarnings(timeBlockWarn)
+	if err != nil {
+		panic(err)
+	}
+	notifier, err := NewNotifier(notify, notifyCmd)
+	if err != nil {
+		panic(err)
+	}
+	var timesheet TimesheetConfig
+	if timesheet.Rounding, err = time.ParseDuration(timesheetRound); err != nil {
+		panic(err)
+	}
+	if timesheet.Projects, err = ParseTimesheetProjects(timesheetProjects); err != nil {
+		panic(err)
+	}
 
 	// logger
 	var w io.Writer
@@ -78,9 +131,13 @@ func main() {
 		logger.Error("failed database open", "error", err)
 		os.Exit(1)
 	}
-	if err := conn.RunMigrations(migrations); err != nil {
-		logger.Error("failed migration", "error", err)
-		os.Exit(1)
+	// /status is rendered on every shell prompt, so it leaves migrating to
+	// the other commands
+	if len(os.Args) < 2 || os.Args[1] != "/status" {
+		if err := conn.RunMigrations(migrations); err != nil {
+			logger.Error("failed migration", "error", err)
+			os.Exit(1)
+		}
 	}
 	defer conn.Close() //nolint:errcheck
 
@@ -89,16 +146,31 @@ func main() {
 	// repos
 	taskRepo := sqlite.NewTaskRepo(dbGetter, logger)
 	syncSessionRepo := sqlite.NewSyncSessionRepo(dbGetter, logger)
+	recurrenceRepo := sqlite.NewRecurrenceRepo(dbGetter, logger)
+	pauseRepo := sqlite.NewPauseRepo(dbGetter, logger)
+	taskActionRepo := sqlite.NewTaskActionRepo(dbGetter, logger)
+
+	// hooks
+	var hooks *HookRunner
+	if !noHooks {
+		hooks = NewHookRunner(hooksDir, cmdTo, logger)
+	}
+	defer hooks.Wait()
 
 	// svcs
-	taskSvc := NewTaskSvc(transactor, logger, taskRepo, syncSessionRepo)
+	taskSvc := NewTaskSvc(transactor, logger, taskRepo, syncSessionRepo, recurrenceRepo, pauseRepo, taskActionRepo, hooks)
 
 	// handle initial args
 	timeout, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	opts, err := parseProgramArgs(timeout, taskSvc)
+	opts, err := parseProgramArgs(timeout, taskSvc, timeFormat, statusFormat, socketPath, queueStrategy, timesheet, jsonOutput)
 	if err != nil {
-		fmt.Println(err)
+		if jsonOutput {
+			printCLIError(os.Stdout, err, true)
+		} else {
+			printCLIError(os.Stderr, err, false)
+		}
+		hooks.Wait()
 		os.Exit(1)
 	}
 	if opts.showHelp {
@@ -106,6 +178,7 @@ func main() {
 		os.Exit(0)
 	}
 	if opts.shouldExit {
+		hooks.Wait()
 		os.Exit(0)
 	}
 
@@ -113,16 +186,46 @@ func main() {
 	fmt.Println(colorize(colorYellow, logo))
 	fmt.Printf("\nEnter \"/h\" for help\n\n")
 
+	var history []string
+	if historyPath != "" {
+		if history, err = LoadInputHistory(historyPath); err != nil {
+			logger.Warn("failed to load input history", "error", err)
+		}
+	}
 	m := NewModel(taskSvc, opts.tasks, logger, modelOptions{
-		cmdTimeout:    cmdTo,
-		timeFormat:    timeFormat,
-		syncServerURL: syncServerURL,
-		syncRate:      sr,
+		cmdTimeout:        cmdTo,
+		timeFormat:        timeFormat,
+		syncServerURL:     syncServerURL,
+		syncRate:          sr,
+		queueStrategy:     queueStrategy,
+		requeueSubtasks:   shouldRequeueSubtasks,
+		idleTimeout:       idleTo,
+		pomoBreakAction:   breakAction,
+		notifier:          notifier,
+		timeBlockWarnings: timeBlockWarnings,
+		historyPath:       historyPath,
+		history:           history,
 	})
 	p := tea.NewProgram(m)
+	hooks.OnError(func(err error) {
+		p.Send(ErrorMsg{
+			err: err,
+		})
+	})
+	if ln, err := listenAPI(socketPath); err != nil {
+		logger.Warn("api socket disabled", "error", err)
+	} else {
+		defer ln.Close() //nolint:errcheck
+		go func() {
+			if err := serveAPI(ln, &API{send: p.Send, timeout: 2 * cmdTo}); err != nil {
+				logger.Error("api socket failed", "error", err)
+			}
+		}()
+	}
 	if _, err := p.Run(); err != nil {
 		logger.Error(err.Error())
 	}
+	hooks.OnError(nil)
 }
 
 type programOptions struct {
@@ -131,7 +234,7 @@ type programOptions struct {
 	shouldExit bool
 }
 
-func parseProgramArgs(ctx context.Context, taskSvc TaskSvc) (programOptions, error) {
+func parseProgramArgs(ctx context.Context, taskSvc TaskSvc, timeFormat, statusFormat, socketPath string, queueStrategy DequeueStrategy, timesheet TimesheetConfig, jsonOutput bool) (programOptions, error) {
 	var opts programOptions
 
 	if len(os.Args) == 1 {
@@ -150,28 +253,131 @@ func parseProgramArgs(ctx context.Context, taskSvc TaskSvc) (programOptions, err
 
 	logger.Debug("parsed program args", "cmd", cmd, "arg", arg)
 	switch cmd {
-	case "/n", "":
-		if arg != "" {
-			t := TaskFromName(arg)
-			t.StartedAt = time.Now()
-			opts.tasks = append(opts.tasks, t)
-			return opts, nil
+	case "":
+		t := TaskFromName(arg)
+		t.StartedAt = time.Now()
+		opts.tasks = append(opts.tasks, t)
+		return opts, nil
+	case "/n", "/x", "/k", "/note", "/end", "/status":
+		if cmd == "/status" && arg == "" {
+			arg = statusFormat
 		}
-
+		out, forwarded, err := forwardTaskCommand(socketPath, cmd, arg)
+		if !forwarded {
+			out, err = runTaskCommand(ctx, taskSvc, queueStrategy, cmd, arg)
+		}
+		if err != nil {
+			return programOptions{}, err
+		}
+		if err := out.print(os.Stdout, jsonOutput); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
 		return opts, nil
 	case "/a":
+		if arg == "" {
+			return programOptions{}, fmt.Errorf("usage: daygo /a <task>")
+		}
 		t := TaskFromName(arg)
-		t.UpdatedAt = time.Now()
-		_, err := taskSvc.UpsertTask(ctx, t)
+		queued, err := taskSvc.QueueTask(ctx, t)
+		if err != nil {
+			return programOptions{}, err
+		}
+		out := cliOutput{
+			message: fmt.Sprintf(`Queued up "%s"`, arg),
+			Queued:  taskJSON(queued),
+		}
+		if err := out.print(os.Stdout, jsonOutput); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/log":
+		t, err := parseLoggedTask(arg, time.Now())
+		if err != nil {
+			return programOptions{}, err
+		}
+		logged, err := taskSvc.LogTask(ctx, t)
+		if err != nil {
+			return programOptions{}, err
+		}
+		out := cliOutput{
+			message: fmt.Sprintf(`Logged "%s" %s-%s`, logged.Name, logged.StartedAt.Format(timeFormat), logged.EndedAt.Format(timeFormat)),
+			Ended:   taskJSON(logged),
+		}
+		if err := out.print(os.Stdout, jsonOutput); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/recur":
+		out, err := runRecurCommand(ctx, taskSvc, arg)
+		if err != nil {
+			return programOptions{}, err
+		}
+		fmt.Println(out)
+		opts.shouldExit = true
+		return opts, nil
+	case "/export":
+		if err := runExport(ctx, taskSvc, os.Args[2:], os.Stdout, timeFormat); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/report":
+		if err := runReport(ctx, taskSvc, os.Args[2:], os.Stdout, jsonOutput); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/timesheet":
+		if err := runTimesheet(ctx, taskSvc, os.Args[2:], os.Stdout, timesheet); err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/import":
+		res, err := runImport(ctx, taskSvc, os.Args[2:])
+		if err != nil {
+			return programOptions{}, err
+		}
+		fmt.Println(res)
+		opts.shouldExit = true
+		return opts, nil
+	case "/find":
+		if arg == "" {
+			return programOptions{}, fmt.Errorf("usage: daygo /find <query>")
+		}
+		tasks, err := taskSvc.SearchTasks(ctx, arg, maxFindResults)
+		if err != nil {
+			return programOptions{}, err
+		}
+		if jsonOutput {
+			err = exportJSON(os.Stdout, tasks)
+		} else {
+			_, err = fmt.Println(renderFindResults(arg, tasks, timeFormat))
+		}
+		if err != nil {
+			return programOptions{}, err
+		}
+		opts.shouldExit = true
+		return opts, nil
+	case "/r", "/review":
+		var daysAgo int
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return programOptions{}, fmt.Errorf("usage: daygo /r [days_ago]")
+			}
+			daysAgo = n
+		}
+		out, err := runReview(ctx, taskSvc, daysAgo, timeFormat)
 		if err != nil {
 			return programOptions{}, err
 		}
-		fmt.Printf(`Queued up "%s"`+"\n", arg)
+		fmt.Println(out)
 		opts.shouldExit = true
 		return opts, nil
-	case "/review":
-		// TODO /review
-		panic("review command not implemented")
 	default:
 		opts.showHelp = true
 		return opts, nil
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS recurrences (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    spec TEXT NOT NULL,
+    next_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    deleted_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurrences_updated_at ON recurrences(updated_at);
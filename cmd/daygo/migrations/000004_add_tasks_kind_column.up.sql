@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN kind INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_continues_id;
+ALTER TABLE tasks DROP COLUMN continues_id;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN continues_id TEXT REFERENCES tasks(id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_continues_id ON tasks(continues_id);
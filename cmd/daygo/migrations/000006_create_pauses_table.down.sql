@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pauses_task_id;
+DROP TABLE IF EXISTS pauses;
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN pomodoros INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN time_block_at INTEGER;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_actions_created_at;
+DROP TABLE IF EXISTS task_actions;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS task_actions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_actions_created_at ON task_actions(created_at);
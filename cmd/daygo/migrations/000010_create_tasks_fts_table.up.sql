@@ -0,0 +1,19 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(
+    name,
+    task_id UNINDEXED
+);
+
+INSERT INTO tasks_fts (name, task_id) SELECT name, id FROM tasks;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (name, task_id) VALUES (new.name, new.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF name ON tasks BEGIN
+    DELETE FROM tasks_fts WHERE task_id = old.id;
+    INSERT INTO tasks_fts (name, task_id) VALUES (new.name, new.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM tasks_fts WHERE task_id = old.id;
+END;
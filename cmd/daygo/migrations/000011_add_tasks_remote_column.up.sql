@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN remote INTEGER NOT NULL DEFAULT 0;
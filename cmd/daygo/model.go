@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/benjamonnguyen/daygo"
+	"github.com/benjamonnguyen/daygo/sqlite"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/timer"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -33,23 +35,60 @@ const programUsage = `Usage:
   daygo: start next queued task
   daygo <task>: start new task
   daygo /a <task>: add task to queue
-  daygo /r [days_ago]: review tasks for date some number of days ago (default 0)`
+  daygo /n [task]: end current task and start a new one without opening daygo; if task is not provided, one will be dequeued
+  daygo /k: skip current task
+  daygo /x: delete current task
+  daygo /note <text>: add a note to the current task
+  daygo /log [YYYY-MM-DD] <HHMM>-<HHMM> <task>[; [HHMM] <note>...]: record a task done earlier (default today) with optional notes
+  daygo /end: end current task
+  daygo /status [format]: show current task, optionally as a text/template (e.g. "{{.Name}} {{.Elapsed}}"); default format is DAYGO_STATUS_FORMAT
+  daygo /recur [<spec> <task> | rm <n>]: list, add or remove recurring tasks
+  daygo /export [--format json|csv|md|ics] [--from YYYY-MM-DD] [--to YYYY-MM-DD] [--tag <tag>]: export tasks started within days (default today)
+  daygo /import <file> [--format json|todotxt|taskwarrior-json|lines] [--completed skip|history]: import tasks exported as JSON, or queue tasks from other tools
+  daygo /find <query>: search past tasks and notes, most relevant first
+  daygo /r [days_ago]: review tasks for date some number of days ago (default 0)
+  daygo /report [--week|--month|--from YYYY-MM-DD [--to YYYY-MM-DD]] [--group-by tag|day]: time tracked and tasks done, skipped and discarded (default today)
+  daygo /timesheet [--format toggl|clockify] [--week|--month|--from YYYY-MM-DD [--to YYYY-MM-DD]] [--round <duration>] [--email <email>]: CSV timesheet for importing into a time tracker
+
+  --json: print results of commands as JSON
+  --no-hooks: don't run lifecycle hooks in ~/.daygo/hooks (or DAYGO_HOOKS_DIR)`
 
 const commandHelp = `COMMANDS:
   /n [task]: end current task and start a new one; if task is not provided, one will be dequeued
+  /n@<HHMM> [task]: like /n, as of an earlier time today
+  /log [YYYY-MM-DD] <HHMM>-<HHMM> <task>[; [HHMM] <note>...]: record a task done earlier (default today) with optional notes
   /k: skip current task
+  /p: pause current task, or resume it if paused
+  /split <follow-up>: end current task and queue a follow-up that continues it
   /x: delete current task or note
 
   <note>: add a note to the current task
+  /sub <subtask>: add a checklist item to the current task
+  /done <n>: check off subtask n of the current task
   /a <task>: add task to the queue
-  /e <edit>: edit text of current item
-  /t <HHMM>: set a time to auto-end task
+  /e <edit>: edit text of the selected or current item; "/e start|end <HHMM>" to change its start or end time
+  /u [n]: undo the last n (default 1) deletes, skips, ends and edits
+  /t <HHMM|duration>: set a time (24h) or duration (25m, 1h30) to auto-end task; "/t +15m" to extend, "/t off" to clear
+  /pomo [work] [break]: cycle pomodoro work/break intervals in minutes (default 25 5); "/pomo off" to stop
   /f [tag]: filter task queue by tag; if no tag provided, clear filter
+  /again [search]: queue the last ended task again, or the past task best matching search
+  /find <query>: search past tasks and notes, most relevant first
+  /recur [<spec> <task> | rm <n>]: list, add or remove recurring tasks; spec: daily|weekdays|every <N>d|weekly <mon,tue,...>
+  /mode [fifo|rr|weighted <tag>:<weight>...]: set how tasks are dequeued across tags; if no mode provided, show current mode
 
   /o: end program without saving
+
+  tab, shift+tab: complete commands, #tags and past tasks, cycling through matches
+  up, down: recall lines entered in this and previous sessions
+  shift+up, shift+down: select an earlier task or note to edit; esc to clear
 `
 
-var timeRe = regexp.MustCompile(`^(?:[01]\d|2[0-3])[0-5]\d$`)
+const (
+	recurrenceCheckRate = time.Minute
+	// claimAlertRate is how often a failure to claim recurrences from the sync
+	// server is alerted while the claims are retried
+	claimAlertRate = 30 * time.Minute
+)
 
 type model struct {
 	// children
@@ -65,9 +104,31 @@ type model struct {
 	// state
 	taskQueue TaskQueue
 	taskLog   []Task
-	alerts    []string
-	quitting  bool
-	h         int
+	// taskNames are past task names suggested as input, most recently used first
+	taskNames  []string
+	completion completion
+	history    inputHistory
+	alerts     []string
+	quitting   bool
+	h          int
+	// lastActivity is when the last key was received
+	lastActivity time.Time
+	// idleSince is set while prompting the user about time spent away
+	idleSince time.Time
+	// undoLog are the actions /u can reverse, oldest first
+	undoLog []undoAction
+	// selected is the task log item under the cursor, nil if none
+	selected *logItem
+	// lastEnded is the task that ended last before this run, if any
+	lastEnded Task
+	// starting are the start times of new tasks that are being persisted
+	starting []time.Time
+	// quitOnStart holds off quitting until the current task's start is
+	// persisted, then discards the task if discardOnQuit
+	quitOnStart   bool
+	discardOnQuit bool
+	// claimAlertedAt is when a failure to claim a recurrence was last alerted
+	claimAlertedAt time.Time
 }
 
 type modelOptions struct {
@@ -75,6 +136,20 @@ type modelOptions struct {
 	timeFormat    string
 	syncServerURL string
 	syncRate      time.Duration
+	queueStrategy DequeueStrategy
+	// requeueSubtasks queues unfinished subtasks as tasks when their parent ends
+	requeueSubtasks bool
+	// idleTimeout is how long without input before prompting about idle time; 0 disables
+	idleTimeout     time.Duration
+	pomoBreakAction PomoBreakAction
+	// notifier is nil if notifications are disabled
+	notifier Notifier
+	// timeBlockWarnings are how long before a time block expires to warn
+	timeBlockWarnings []time.Duration
+	// historyPath is where entered lines are saved; empty disables saving
+	historyPath string
+	// history are the lines entered in previous sessions, oldest first
+	history []string
 }
 
 func NewModel(taskSvc TaskSvc, initialTasks []Task, logger daygo.Logger, opts modelOptions) model {
@@ -82,6 +157,11 @@ func NewModel(taskSvc TaskSvc, initialTasks []Task, logger daygo.Logger, opts mo
 	userinput.Focus()
 	userinput.CharLimit = 280
 	userinput.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+	userinput.ShowSuggestions = true
+	// tab and up/down are handled by the model for cycling and history
+	userinput.KeyMap.AcceptSuggestion = key.NewBinding(key.WithDisabled())
+	userinput.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	userinput.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
 
 	return model{
 		taskSvc: taskSvc,
@@ -91,6 +171,9 @@ func NewModel(taskSvc TaskSvc, initialTasks []Task, logger daygo.Logger, opts mo
 
 		vp:        viewport.New(0, 0),
 		userinput: userinput,
+		history:   newInputHistory(opts.history),
+
+		lastActivity: time.Now(),
 	}
 }
 
@@ -103,14 +186,36 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var tiCmd, vpCmd, tbCmd, cmd tea.Cmd
+	var tiCmd, vpCmd, tbCmd, notifyCmd, cmd tea.Cmd
+	value := m.userinput.Value()
+
+	if _, ok := msg.(tea.KeyMsg); ok && m.quitOnStart {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.Type != tea.KeyCtrlC {
+		var handled bool
+		m, cmd, handled = m.updateIdle(msg)
+		if handled {
+			return m, cmd
+		}
+	}
 
 	m, cmd = m.updateParent(msg)
 
 	// update children
 
 	m.userinput, tiCmd = m.userinput.Update(msg)
+	if m.userinput.Value() != value {
+		m.updateSuggestions()
+	}
 	m.tbTimer.Model, tbCmd = m.tbTimer.Update(msg)
+	if w, ok := m.tbTimer.popWarning(); ok {
+		if t := m.currentTask(); t.IsPending() {
+			m.addAlert(colorYellow, "%s left in time block for \"%s\"", formatDuration(w), t.Name)
+			m.resizeViewport()
+			notifyCmd = m.notify(EventTimeBlockWarning, t, "%s left in time block", formatDuration(w))
+		}
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -119,13 +224,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.vp, vpCmd = m.vp.Update(msg)
 	}
 
-	return m, tea.Batch(tiCmd, vpCmd, cmd, tbCmd)
+	return m, tea.Batch(tiCmd, vpCmd, cmd, tbCmd, notifyCmd)
 }
 
 func (m model) updateParent(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ErrorMsg:
-		m.addAlert(colorRed, msg.err.Error())
+		m.addAlert(colorRed, "%s", msg.err.Error())
 		m.l.Error(msg.err)
 		var cmd tea.Cmd
 		if msg.isFatal {
@@ -134,28 +239,26 @@ func (m model) updateParent(msg tea.Msg) (model, tea.Cmd) {
 		return m, cmd
 	case timer.TimeoutMsg:
 		if msg.ID == m.tbTimer.ID() {
+			if m.tbTimer.pomo != nil {
+				return m.advancePomodoro()
+			}
 			ended, err := m.endPendingTask()
-			return m, func() tea.Msg {
-				if err != nil {
+			if err != nil {
+				return m, func() tea.Msg {
 					return ErrorMsg{
 						err: err,
 					}
 				}
-				timeout, cancel := m.newTimeout()
-				defer cancel()
-				_, err := m.taskSvc.UpsertTask(timeout, ended)
-				if err != nil {
-					return ErrorMsg{
-						err: err,
-					}
-				}
-				return nil
 			}
+			return m, tea.Batch(
+				m.persistEndedTask(ended),
+				m.notify(EventTimeBlockExpired, &ended, "Time block ended \"%s\"", ended.Name),
+			)
 		}
 		return m, nil
 	case SyncMsg:
 		if msg.err != "" {
-			m.addAlert(colorRed, msg.err)
+			m.addAlert(colorRed, "%s", msg.err)
 		}
 		if msg.toServerSyncCount > 0 {
 			m.addAlert(colorCyan, "Synced %d tasks to server", msg.toServerSyncCount)
@@ -172,6 +275,62 @@ func (m model) updateParent(msg tea.Msg) (model, tea.Cmd) {
 		m.taskQueue.Queue(msg.task)
 		m.addAlert(colorCyan, "Queued \"%s\"", msg.task.Name)
 		return m, nil
+	case TaskLoggedMsg:
+		m.insertLoggedTask(msg.task)
+		m.addAlert(colorCyan, "Logged \"%s\" %s-%s", msg.task.Name, msg.task.StartedAt.Format(m.opts.timeFormat), msg.task.EndedAt.Format(m.opts.timeFormat))
+		return m, nil
+	case TaskStartedMsg:
+		cmd := m.applyStartedTask(msg.task, msg.err)
+		if t := m.currentTask(); m.quitOnStart && !(t.IsPending() && m.isStarting(*t)) {
+			m, quit := m.endProgram(m.discardOnQuit)
+			return m, tea.Batch(cmd, quit)
+		}
+		return m, cmd
+	case TaskEditedMsg:
+		m.applyEditedTask(msg)
+		return m, nil
+	case PausesSavedMsg:
+		m.applySavedPauses(msg.taskID, msg.pauses)
+		return m, nil
+	case SubtasksQueuedMsg:
+		for _, t := range msg.tasks {
+			m.taskQueue.Queue(t)
+		}
+		m.addAlert(colorCyan, "Queued %d unfinished subtasks", len(msg.tasks))
+		return m, nil
+	case TaskSplitMsg:
+		m.taskQueue.Queue(msg.followUp)
+		m.addAlert(colorCyan, "Queued \"%s\"", msg.followUp.Name)
+		if len(msg.requeued) > 0 {
+			for _, t := range msg.requeued {
+				m.taskQueue.Queue(t)
+			}
+			m.addAlert(colorCyan, "Queued %d unfinished subtasks", len(msg.requeued))
+		}
+		return m, nil
+	case RecurrenceMsg:
+		now := time.Now()
+		for _, err := range msg.errs {
+			m.l.Error(err)
+			if errors.Is(err, ErrClaimFailed) {
+				if now.Sub(m.claimAlertedAt) < claimAlertRate {
+					continue
+				}
+				m.claimAlertedAt = now
+			}
+			m.addAlert(colorRed, "%s", err)
+		}
+		for _, t := range msg.tasksToQueue {
+			m.taskQueue.Queue(t)
+			m.addAlert(colorCyan, "Queued recurring \"%s\"", t.Name)
+		}
+		return m, tea.Tick(recurrenceCheckRate, func(time.Time) tea.Msg {
+			return m.spawnRecurrences()
+		})
+	case AlertMsg:
+		m.addAlert(msg.color, "%s", msg.msg)
+		m.resizeViewport()
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.h = msg.Height
 		m.userinput.Width = msg.Width
@@ -180,33 +339,106 @@ func (m model) updateParent(msg tea.Msg) (model, tea.Cmd) {
 		return m, nil
 	case InitTaskQueueMsg:
 		m.taskQueue = NewTaskQueue(msg.tasks)
+		m.taskQueue.SetStrategy(m.opts.queueStrategy)
+		m.taskNames = msg.taskNames
+		m.updateSuggestions()
+		if msg.lastEnded != nil {
+			m.lastEnded = *msg.lastEnded
+		}
 
-		if len(m.taskLog) == 0 && m.taskQueue.Size() > 0 {
-			t := m.taskQueue.Dequeue()
-			t.StartedAt = time.Now()
-			m.taskLog = append(m.taskLog, t)
+		cmds := []tea.Cmd{m.spawnRecurrences}
+		if msg.current != nil {
+			if len(m.taskLog) == 0 {
+				cmds = append(cmds, m.resumeTask(*msg.current))
+			} else {
+				// a new task was started from the command line
+				interrupted := *msg.current
+				interrupted.EndedAt = time.Now()
+				if interrupted.IsPaused() {
+					interrupted.TogglePause(interrupted.EndedAt)
+				}
+				cmds = append(cmds, m.persistEndedTask(interrupted))
+			}
+		}
+		if t := m.currentTask(); t.IsPending() && t.ID == uuid.Nil {
+			cmds = append(cmds, m.startTask(m.removeCurrentTask()))
+		}
+		if !m.currentTask().IsPending() && m.taskQueue.Size() > 0 {
+			cmds = append(cmds, m.startTask(m.taskQueue.Dequeue()))
 		}
 
 		m.vp.SetContent(m.renderVisibleTasks())
 		m.resizeViewport()
-		return m, nil
+		return m, tea.Batch(cmds...)
 	case EndProgramMsg:
 		return m.endProgram(msg.discardPendingTask)
+	case APIRequestMsg:
+		m, cmd := m.handleAPIRequest(msg)
+		m.vp.SetContent(m.renderVisibleTasks())
+		m.resizeViewport()
+		return m, cmd
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyEnter:
 			input := m.userinput.Value()
 			m.userinput.Reset()
+			m.completion = completion{}
 			if input == "" {
 				return m, nil
 			}
 
+			var saveCmd tea.Cmd
+			if m.history.add(input) && m.opts.historyPath != "" {
+				saveCmd = func() tea.Msg {
+					if err := appendInputHistory(m.opts.historyPath, input); err != nil {
+						return ErrorMsg{
+							err: err,
+						}
+					}
+					return nil
+				}
+			}
+
 			var cmd tea.Cmd
 			m.alerts = nil
 			m, cmd = m.handleInput(input)
+			m.selected = nil
 			m.vp.SetContent(m.renderVisibleTasks())
 			m.resizeViewport()
-			return m, cmd
+			return m, tea.Batch(saveCmd, cmd)
+		case tea.KeyTab:
+			m.complete(1)
+			return m, nil
+		case tea.KeyShiftTab:
+			m.complete(-1)
+			return m, nil
+		case tea.KeyUp:
+			if l, ok := m.history.prev(m.userinput.Value()); ok {
+				m.userinput.SetValue(l)
+				m.userinput.CursorEnd()
+			}
+			return m, nil
+		case tea.KeyDown:
+			if l, ok := m.history.next(); ok {
+				m.userinput.SetValue(l)
+				m.userinput.CursorEnd()
+			}
+			return m, nil
+		case tea.KeyShiftUp, tea.KeyShiftDown:
+			step := -1
+			if msg.Type == tea.KeyShiftDown {
+				step = 1
+			}
+			m.moveSelection(step)
+			m.vp.SetContent(m.renderVisibleTasks())
+			return m, nil
+		case tea.KeyEsc:
+			if m.selected != nil {
+				m.selected = nil
+				m.userinput.Reset()
+				m.vp.SetContent(m.renderVisibleTasks())
+			}
+			return m, nil
 		case tea.KeyCtrlC:
 			return m.endProgram(false)
 		}
@@ -214,6 +446,127 @@ func (m model) updateParent(msg tea.Msg) (model, tea.Cmd) {
 	return m, nil
 }
 
+// handleAPIRequest applies a request from the API socket and replies with the
+// affected task
+func (m model) handleAPIRequest(msg APIRequestMsg) (model, tea.Cmd) {
+	reply := func(t *Task, err error) {
+		res := APIResult{Err: err}
+		if t != nil {
+			j := t.JSON()
+			res.Task = &j
+		}
+		msg.reply <- res
+	}
+	if m.taskQueue == nil {
+		reply(nil, fmt.Errorf("task queue is not loaded yet"))
+		return m, nil
+	}
+
+	switch msg.method {
+	case APIQueueTask:
+		t := TaskFromName(msg.arg)
+		return m, func() tea.Msg {
+			timeout, c := m.newTimeout()
+			defer c()
+			inserted, err := m.taskSvc.QueueTask(timeout, t)
+			if err != nil {
+				reply(nil, err)
+				return ErrorMsg{
+					err: err,
+				}
+			}
+			reply(&inserted, nil)
+			return QueueMsg{
+				task: inserted,
+			}
+		}
+	case APIGetCurrentTask:
+		if t := m.currentTask(); t.IsPending() {
+			reply(t, nil)
+		} else {
+			reply(nil, nil)
+		}
+	case APIAddNote:
+		t := m.currentTask()
+		if !t.IsPending() {
+			reply(nil, fmt.Errorf("no pending task"))
+			return m, nil
+		}
+		m.addNote(msg.arg)
+		reply(t, nil)
+	case APIEndTask:
+		ended, err := m.endPendingTask()
+		if err != nil {
+			reply(nil, err)
+			return m, nil
+		}
+		m.tbTimer = timeBlockTimer{}
+		reply(&ended, nil)
+		return m, m.persistEndedTask(ended)
+	case APISetFilter:
+		m.taskQueue.SetFilter(msg.arg)
+		msg.reply <- APIResult{Filter: msg.arg}
+	default:
+		reply(nil, fmt.Errorf("unknown api method %d", msg.method))
+	}
+	return m, nil
+}
+
+// updateIdle prompts the user on return from being idle and handles their
+// choice. Returns true if the key was consumed by the prompt.
+func (m model) updateIdle(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	now := time.Now()
+	lastActivity := m.lastActivity
+	m.lastActivity = now
+
+	t := m.currentTask()
+	if m.idleSince.IsZero() {
+		if m.opts.idleTimeout <= 0 || !t.IsPending() || t.IsPaused() || now.Sub(lastActivity) < m.opts.idleTimeout {
+			return m, nil, false
+		}
+		m.idleSince = lastActivity
+		m.alerts = nil
+		m.resizeViewport()
+		return m, nil, true
+	}
+
+	idle := now.Sub(m.idleSince)
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "k":
+		m.addAlert(colorCyan, "Kept %s idle time", formatDuration(idle))
+	case "d":
+		if t.IsPending() {
+			p := Pause{}
+			p.StartedAt = m.idleSince
+			p.EndedAt = now
+			t.Pauses = append(t.Pauses, p)
+			cmd = m.savePauses()
+			if !t.TimeBlockAt.IsZero() && !m.tbTimer.Timedout() {
+				// push back the time block by the discarded time like /p
+				t.TimeBlockAt = t.TimeBlockAt.Add(idle)
+				m.tbTimer.Timeout += idle
+				m.tbTimer.warnings = pendingWarnings(m.opts.timeBlockWarnings, m.tbTimer.Timeout)
+				cmd = tea.Batch(cmd, m.saveCurrentTask())
+			}
+			m.addAlert(colorCyan, "Discarded %s idle time", formatDuration(idle))
+		}
+	case "e":
+		if ended, err := m.endPendingTaskAt(m.idleSince); err == nil {
+			m.tbTimer = timeBlockTimer{}
+			cmd = m.persistEndedTask(ended)
+			m.addAlert(colorCyan, "Ended \"%s\" at %s", ended.Name, m.idleSince.Format(m.opts.timeFormat))
+		}
+	default:
+		return m, nil, true
+	}
+
+	m.idleSince = time.Time{}
+	m.vp.SetContent(m.renderVisibleTasks())
+	m.resizeViewport()
+	return m, cmd, true
+}
+
 func (m model) initTaskQueue() tea.Msg {
 	timeout, cancel := m.newTimeout()
 	defer cancel()
@@ -225,21 +578,64 @@ func (m model) initTaskQueue() tea.Msg {
 		}
 	}
 
+	var current *Task
+	if t, err := m.taskSvc.GetCurrentTask(timeout); err == nil {
+		current = &t
+	} else if !errors.Is(err, sqlite.ErrNotFound) {
+		return ErrorMsg{
+			err: err,
+		}
+	}
+
+	taskNames, err := m.taskSvc.GetTaskNames(timeout, maxTaskNameSuggestions)
+	if err != nil {
+		return ErrorMsg{
+			err: err,
+		}
+	}
+
+	var lastEnded *Task
+	if t, err := m.taskSvc.GetLastEndedTask(timeout); err == nil {
+		lastEnded = &t
+	} else if !errors.Is(err, sqlite.ErrNotFound) {
+		return ErrorMsg{
+			err: err,
+		}
+	}
+
 	return InitTaskQueueMsg{
-		tasks: tasks,
+		tasks:     tasks,
+		current:   current,
+		taskNames: taskNames,
+		lastEnded: lastEnded,
 	}
 }
 
 func (m model) endProgram(discardPendingTask bool) (model, tea.Cmd) {
+	if t := m.currentTask(); t.IsPending() && m.isStarting(*t) {
+		// the task can only be ended or discarded once it has an ID
+		m.quitOnStart, m.discardOnQuit = true, discardPendingTask
+		return m, nil
+	}
 	m.quitting = true
 	if t := m.currentTask(); t.IsPending() {
 		if discardPendingTask {
-			_ = m.removeCurrentTask()
+			m.discardTask(m.removeCurrentTask())
 		} else {
 			timeout, cancel := m.newTimeout()
 			defer cancel()
 			t.EndedAt = time.Now()
-			if _, err := m.taskSvc.UpsertTask(timeout, *t); err != nil {
+			if t.IsPaused() {
+				t.TogglePause(t.EndedAt)
+			}
+			if ended, err := m.taskSvc.EndTask(timeout, *t); err != nil {
+				logger.Error(err.Error())
+			} else if m.opts.requeueSubtasks {
+				if _, err := m.taskSvc.RequeueUnfinishedSubtasks(timeout, ended); err != nil {
+					logger.Error(err.Error())
+				}
+			}
+			if err := m.notifyNow(newNotifyEvent(EventTaskEnded, t, "Ended \"%s\"", t.Name)); err != nil {
 				logger.Error(err.Error())
 			}
 		}
@@ -253,6 +649,26 @@ func (m model) endProgram(discardPendingTask bool) (model, tea.Cmd) {
 	return m, tea.Quit
 }
 
+// discardTask undoes persisting a started task, returning it to the queue if it was dequeued
+func (m model) discardTask(t Task) {
+	if t.ID == uuid.Nil {
+		return
+	}
+	timeout, cancel := m.newTimeout()
+	defer cancel()
+	var err error
+	if t.QueuedAt.IsZero() {
+		_, err = m.taskSvc.DeleteTask(timeout, t.ID)
+	} else {
+		t.StartedAt = time.Time{}
+		t.TimeBlockAt = time.Time{}
+		_, err = m.taskSvc.SaveTaskRecord(timeout, t)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
 func (m model) checkSyncServerURL() tea.Msg {
 	if m.opts.syncServerURL == "" {
 		return nil
@@ -266,7 +682,25 @@ func (m model) checkSyncServerURL() tea.Msg {
 	return SyncMsg{}
 }
 
+// sync syncs with the server, notifying of any failure
 func (m model) sync() tea.Msg {
+	msg := m.syncWithServer()
+	var syncErr string
+	switch msg := msg.(type) {
+	case ErrorMsg:
+		syncErr = msg.err.Error()
+	case SyncMsg:
+		syncErr = msg.err
+	}
+	if syncErr != "" {
+		if err := m.notifyNow(newNotifyEvent(EventSyncError, nil, "%s", syncErr)); err != nil {
+			m.l.Error(err)
+		}
+	}
+	return msg
+}
+
+func (m model) syncWithServer() tea.Msg {
 	if m.opts.syncServerURL == "" {
 		return nil
 	}
@@ -289,9 +723,17 @@ func (m model) sync() tea.Msg {
 		}
 	}
 
+	recurrencesToSync, err := m.taskSvc.GetRecurrencesToSync(timeout, m.opts.syncServerURL)
+	if err != nil {
+		return ErrorMsg{
+			err: err,
+		}
+	}
+
 	req := daygo.SyncRequest{
-		LastSyncTime: lastSync.CreatedAt,
-		ClientTasks:  tasksToSync,
+		LastSyncTime:      lastSync.CreatedAt,
+		ClientTasks:       tasksToSync,
+		ClientRecurrences: recurrencesToSync,
 	}
 	reqData, err := json.Marshal(req)
 	if err != nil {
@@ -336,6 +778,7 @@ func (m model) sync() tea.Msg {
 	}
 
 	upserted, errs := m.taskSvc.SyncTasks(timeout, syncResp.ServerTasks)
+	errs = append(errs, m.taskSvc.SyncRecurrences(timeout, syncResp.ServerRecurrences)...)
 	if len(errs) > 0 {
 		// has error but still partial sync status
 		session.Status = daygo.SyncStatusPartial
@@ -357,6 +800,58 @@ func (m model) sync() tea.Msg {
 	}
 }
 
+func (m model) spawnRecurrences() tea.Msg {
+	timeout, cancel := m.newTimeout()
+	defer cancel()
+
+	var claim ClaimRecurrenceFunc
+	if m.opts.syncServerURL != "" {
+		claim = m.claimRecurrence
+	}
+	spawned, errs := m.taskSvc.SpawnDueRecurrences(timeout, claim)
+	return RecurrenceMsg{
+		tasksToQueue: spawned,
+		errs:         errs,
+	}
+}
+
+// claimRecurrence claims the recurrence's next occurrence on the sync server
+func (m model) claimRecurrence(ctx context.Context, r daygo.ExistingRecurrenceRecord) (daygo.ExistingRecurrenceRecord, bool, error) {
+	reqData, err := json.Marshal(daygo.ClaimRecurrenceRequest{
+		RecurrenceID: r.ID,
+		OccurrenceAt: r.NextAt,
+	})
+	if err != nil {
+		return daygo.ExistingRecurrenceRecord{}, false, fmt.Errorf("failed to marshal claim request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.opts.syncServerURL+"/recurrences/claim", bytes.NewReader(reqData))
+	if err != nil {
+		return daygo.ExistingRecurrenceRecord{}, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return daygo.ExistingRecurrenceRecord{}, false, fmt.Errorf("failed to make claim request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// not synced to server yet so no other device can claim it
+		return daygo.ExistingRecurrenceRecord{}, true, nil
+	default:
+		return daygo.ExistingRecurrenceRecord{}, false, fmt.Errorf("claim request failed: %s", resp.Status)
+	}
+
+	var claimResp daygo.ClaimRecurrenceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&claimResp); err != nil {
+		return daygo.ExistingRecurrenceRecord{}, false, fmt.Errorf("failed to decode claim response: %w", err)
+	}
+	return claimResp.Recurrence, claimResp.Claimed, nil
+}
+
 func (m model) renderFooter() string {
 	if m.quitting {
 		return ""
@@ -364,7 +859,15 @@ func (m model) renderFooter() string {
 
 	var footer strings.Builder
 	footer.WriteRune('\n')
-	footer.WriteString(m.userinput.View())
+	if !m.idleSince.IsZero() {
+		footer.WriteString(colorize(colorYellow, fmt.Sprintf(
+			"You were away %s: [k]eep, [d]iscard idle time, or [e]nd task at last activity (%s)?",
+			formatDuration(time.Since(m.idleSince)),
+			m.idleSince.Format(m.opts.timeFormat),
+		)))
+	} else {
+		footer.WriteString(m.userinput.View())
+	}
 	footer.WriteString("\n\n")
 
 	showQuit := true
@@ -463,20 +966,64 @@ func (m model) currentTask() *Task {
 
 // endPendingTask returns error if no pending task
 func (m *model) endPendingTask() (Task, error) {
-	now := time.Now()
+	return m.endPendingTaskAt(time.Now())
+}
+
+// endPendingTaskAt ends the pending task at the given time; returns error if no pending task
+func (m *model) endPendingTaskAt(at time.Time) (Task, error) {
 	t := m.currentTask()
 	if !t.IsPending() {
 		return Task{}, fmt.Errorf("no pending task")
 	}
 
-	t.EndedAt = now
+	t.EndedAt = at
 	if n := t.LastNote(); n != nil {
-		n.EndedAt = now
+		n.EndedAt = at
+	}
+	if t.IsPaused() {
+		t.TogglePause(at)
 	}
 
 	return *t, nil
 }
 
+// persistEndedTask upserts the ended task and queues its unfinished subtasks if configured
+func (m model) persistEndedTask(ended Task) tea.Cmd {
+	return tea.Batch(m.upsertEndedTask(ended), m.notify(EventTaskEnded, &ended, "Ended \"%s\"", ended.Name))
+}
+
+func (m model) upsertEndedTask(ended Task) tea.Cmd {
+	if m.isStarting(ended) {
+		// upserted once its start is persisted
+		return nil
+	}
+	return func() tea.Msg {
+		timeout, cancel := m.newTimeout()
+		defer cancel()
+		upserted, err := m.taskSvc.EndTask(timeout, ended)
+		if err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		if !m.opts.requeueSubtasks {
+			return nil
+		}
+		queued, err := m.taskSvc.RequeueUnfinishedSubtasks(timeout, upserted)
+		if err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		if len(queued) == 0 {
+			return nil
+		}
+		return SubtasksQueuedMsg{
+			tasks: queued,
+		}
+	}
+}
+
 func (m *model) resizeViewport() {
 	tasksHeight := lipgloss.Height(m.renderVisibleTasks())
 	footerHeight := lipgloss.Height(m.renderFooter())
@@ -493,7 +1040,18 @@ func (m model) renderVisibleTasks() string {
 	for i := len(m.taskLog) - 1; i >= 0 && availableHeight >= 0; i-- {
 		line, h := m.taskLog[i].Render(m.opts.timeFormat)
 		availableHeight -= h
-		if i != len(m.taskLog)-1 {
+		if m.selected != nil && m.selected.task == i {
+			// the task's line is followed by its notes'
+			taskLines := strings.Split(line, "\n")
+			for j := range taskLines {
+				if j == m.selected.note+1 {
+					taskLines[j] = selectedStyle.Render(taskLines[j])
+				} else if i != len(m.taskLog)-1 {
+					taskLines[j] = faintStyle.Render(taskLines[j])
+				}
+			}
+			line = strings.Join(taskLines, "\n")
+		} else if i != len(m.taskLog)-1 {
 			line = faintStyle.Render(line)
 		}
 		lines = append(lines, line)
@@ -503,12 +1061,217 @@ func (m model) renderVisibleTasks() string {
 	return strings.Join(lines, "\n")
 }
 
-func (m *model) startNewTask(task string) Task {
-	_, _ = m.endPendingTask()
-	t := TaskFromName(task)
-	t.StartedAt = time.Now()
+// startTask makes t the current task, persisting it as in progress so that
+// it is resumed if the program exits without ending it
+func (m *model) startTask(t Task) tea.Cmd {
+	return m.startTaskAt(t, time.Now())
+}
+
+// startTaskAt is startTask as of the given time
+func (m *model) startTaskAt(t Task, at time.Time) tea.Cmd {
+	t.StartedAt = at
+	t.TimeBlockAt = time.Time{}
+	if m.tbTimer.pomo == nil {
+		m.tbTimer = timeBlockTimer{}
+	}
+
+	m.taskLog = append(m.taskLog, t)
+	m.rememberTaskName(t.Name)
+	if t.ID == uuid.Nil {
+		m.starting = append(m.starting, t.StartedAt)
+	}
+	return tea.Batch(m.persistStartedTask(t), m.notify(EventTaskStarted, &t, "Started \"%s\"", t.Name))
+}
+
+func (m model) persistStartedTask(t Task) tea.Cmd {
+	return func() tea.Msg {
+		timeout, cancel := m.newTimeout()
+		defer cancel()
+		saved, err := m.taskSvc.StartTask(timeout, t)
+		if t.ID != uuid.Nil {
+			// the task was dequeued so its ID is known
+			if err != nil {
+				return ErrorMsg{err: err}
+			}
+			return nil
+		}
+		if err != nil {
+			return TaskStartedMsg{task: t, err: err}
+		}
+		t.ExistingTaskRecord = saved.ExistingTaskRecord
+		return TaskStartedMsg{task: t}
+	}
+}
+
+// isStarting reports whether t is a new task that is still being persisted
+func (m model) isStarting(t Task) bool {
+	return t.ID == uuid.Nil && slices.ContainsFunc(m.starting, t.StartedAt.Equal)
+}
+
+// applyStartedTask gives the new task in the log the ID it was persisted with.
+// The task is upserted if it ended in the meantime, or deleted if it was
+// removed from the log.
+func (m *model) applyStartedTask(started Task, err error) tea.Cmd {
+	m.starting = slices.DeleteFunc(slices.Clone(m.starting), started.StartedAt.Equal)
+	if err != nil {
+		m.addAlert(colorRed, "%s", err)
+		m.l.Error(err)
+	}
+	i := slices.IndexFunc(m.taskLog, func(t Task) bool {
+		return t.ID == uuid.Nil && t.StartedAt.Equal(started.StartedAt)
+	})
+	if i < 0 {
+		if err != nil {
+			return nil
+		}
+		return func() tea.Msg {
+			timeout, cancel := m.newTimeout()
+			defer cancel()
+			if _, err := m.taskSvc.DeleteTask(timeout, started.ID); err != nil {
+				return ErrorMsg{err: err}
+			}
+			return nil
+		}
+	}
+	if err == nil {
+		setRecord := func(t *Task) {
+			t.ID, t.CreatedAt, t.UpdatedAt = started.ID, started.CreatedAt, started.UpdatedAt
+		}
+		setRecord(&m.taskLog[i])
+		for j, a := range m.undoLog {
+			if a.task.ID == uuid.Nil && a.task.StartedAt.Equal(started.StartedAt) {
+				setRecord(&m.undoLog[j].task)
+			}
+			if a.started.ID == uuid.Nil && a.started.StartedAt.Equal(started.StartedAt) {
+				setRecord(&m.undoLog[j].started)
+			}
+		}
+	}
+	if m.taskLog[i].IsPending() {
+		return m.savePauses()
+	}
+	return m.upsertEndedTask(m.taskLog[i])
+}
+
+// savePauses persists the current task's pauses so that a pause outlasts a
+// restart. A task that isn't persisted yet has them saved once it is.
+func (m model) savePauses() tea.Cmd {
+	t := m.currentTask()
+	if !t.IsPending() || t.ID == uuid.Nil || len(t.Pauses) == 0 {
+		return nil
+	}
+	taskID, pauses := t.ID, slices.Clone(t.Pauses)
+	return func() tea.Msg {
+		timeout, cancel := m.newTimeout()
+		defer cancel()
+		saved, err := m.taskSvc.SavePauses(timeout, taskID, pauses)
+		if err != nil {
+			return ErrorMsg{err: err}
+		}
+		return PausesSavedMsg{taskID: taskID, pauses: saved}
+	}
+}
+
+// applySavedPauses gives the task's pauses the IDs they were persisted with
+func (m *model) applySavedPauses(taskID uuid.UUID, saved []Pause) {
+	i := slices.IndexFunc(m.taskLog, func(t Task) bool { return t.ID == taskID })
+	if i < 0 {
+		return
+	}
+	pauses := m.taskLog[i].Pauses
+	for j := range min(len(pauses), len(saved)) {
+		if pauses[j].ID == 0 && pauses[j].StartedAt.Unix() == saved[j].StartedAt.Unix() {
+			pauses[j].ID, pauses[j].CreatedAt = saved[j].ID, saved[j].CreatedAt
+		}
+	}
+}
+
+// rememberTaskName moves name to the front of the suggested task names
+func (m *model) rememberTaskName(name string) {
+	if i := slices.Index(m.taskNames, name); i >= 0 {
+		m.taskNames = slices.Delete(m.taskNames, i, i+1)
+	}
+	m.taskNames = slices.Insert(m.taskNames, 0, name)
+	if len(m.taskNames) > maxTaskNameSuggestions {
+		m.taskNames = m.taskNames[:maxTaskNameSuggestions]
+	}
+	m.updateSuggestions()
+}
+
+// updateSuggestions sets the input's suggestions for its current value
+func (m *model) updateSuggestions() {
+	m.userinput.SetSuggestions(m.inputSuggestions(m.userinput.Value()))
+}
+
+// notify sends an event to the configured notifier if any
+func (m model) notify(typ NotifyEventType, t *Task, format string, args ...any) tea.Cmd {
+	if m.opts.notifier == nil {
+		return nil
+	}
+	e := newNotifyEvent(typ, t, format, args...)
+	return func() tea.Msg {
+		if err := m.notifyNow(e); err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		return nil
+	}
+}
+
+func (m model) notifyNow(e NotifyEvent) error {
+	if m.opts.notifier == nil {
+		return nil
+	}
+	timeout, cancel := m.newTimeout()
+	defer cancel()
+	return m.opts.notifier.Notify(timeout, e)
+}
+
+// resumeTask continues a task left in progress by a previous run, restoring
+// its time block or ending it if the time block expired in the meantime
+func (m *model) resumeTask(t Task) tea.Cmd {
+	now := time.Now()
+	if t.TimeBlockAt.IsZero() {
+		m.taskLog = append(m.taskLog, t)
+		return nil
+	}
+
+	remaining := t.TimeBlockAt.Sub(now)
+	if t.IsPaused() {
+		remaining = t.TimeBlockAt.Sub(t.Pauses[len(t.Pauses)-1].StartedAt)
+	} else if remaining <= 0 {
+		t.EndedAt = t.TimeBlockAt
+		m.taskLog = append(m.taskLog, t)
+		m.addAlert(colorCyan, "Ended \"%s\" at end of time block %s", t.Name, t.TimeBlockAt.Format(m.opts.timeFormat))
+		return m.persistEndedTask(t)
+	}
+
 	m.taskLog = append(m.taskLog, t)
-	return t
+	m.tbTimer = newTimeBlockTimer(remaining, m.opts.timeBlockWarnings)
+	if t.IsPaused() {
+		return m.tbTimer.Stop()
+	}
+	return m.tbTimer.Init()
+}
+
+// saveCurrentTask persists changes to the current task's record
+func (m model) saveCurrentTask() tea.Cmd {
+	t := m.currentTask()
+	if t == nil || t.ID == uuid.Nil {
+		return nil
+	}
+	toSave := *t
+	return func() tea.Msg {
+		timeout, cancel := m.newTimeout()
+		defer cancel()
+		if _, err := m.taskSvc.SaveTaskRecord(timeout, toSave); err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		return nil
+	}
 }
 
 func (m *model) addNote(note string) {
@@ -526,6 +1289,29 @@ func (m *model) addNote(note string) {
 	parent.Notes = append(parent.Notes, Note(n))
 }
 
+func (m *model) addSubtask(name string) {
+	parent := m.currentTask()
+	sub := Subtask{}
+	sub.Name = name
+	sub.Kind = daygo.TaskKindSubtask
+	sub.StartedAt = time.Now()
+	parent.Subtasks = append(parent.Subtasks, sub)
+}
+
+// completeSubtask checks off the nth subtask of the current task
+func (m *model) completeSubtask(n int) error {
+	t := m.currentTask()
+	if n < 1 || n > len(t.Subtasks) {
+		return fmt.Errorf("no subtask #%d", n)
+	}
+	sub := &t.Subtasks[n-1]
+	if sub.IsDone() {
+		return fmt.Errorf("subtask #%d is already done", n)
+	}
+	sub.EndedAt = time.Now()
+	return nil
+}
+
 func (m *model) deleteLastPendingTaskItem() daygo.ExistingTaskRecord {
 	currentTask := m.currentTask()
 	if !currentTask.IsPending() {
@@ -559,36 +1345,133 @@ func (m *model) removeLastNote() Note {
 	return Note{}
 }
 
-func (m *model) timeBlockPendingTask(arg string) {
-	if !timeRe.MatchString(arg) {
-		m.addAlert(colorYellow, "usage: /t <HHMM>")
-		return
-	}
+// timeBlockPendingTask sets, extends or clears the time at which the pending task auto-ends
+func (m *model) timeBlockPendingTask(arg string) tea.Cmd {
 	task := m.currentTask()
 	if !task.IsPending() {
 		m.addAlert(colorRed, "no pending task to time block")
-		return
+		return nil
 	}
-	now, _ := time.Parse("1504", time.Now().Format("1504"))
-	endTime, _ := time.Parse("1504", arg)
+	now := time.Now()
 
-	if endTime.Compare(now) < 0 {
-		endTime = endTime.Add(12 * time.Hour)
+	switch {
+	case arg == "off":
+		if task.TimeBlockAt.IsZero() {
+			m.addAlert(colorRed, "no time block to clear")
+			return nil
+		}
+		task.TimeBlockAt = time.Time{}
+		m.tbTimer = timeBlockTimer{}
+		m.addAlert(colorCyan, "Cleared time block")
+		return m.saveCurrentTask()
+	case strings.HasPrefix(arg, "+"):
+		d, err := parseTimeBlockDuration(arg[1:])
+		if err != nil {
+			m.addAlert(colorYellow, "%s: %s", timeBlockUsage, err)
+			return nil
+		}
+		if task.TimeBlockAt.IsZero() || m.tbTimer.pomo != nil {
+			m.addAlert(colorRed, "%s", errNoTimeBlock)
+			return nil
+		}
+		task.TimeBlockAt = task.TimeBlockAt.Add(d)
+		m.tbTimer.Timeout += d
+		m.tbTimer.warnings = pendingWarnings(m.opts.timeBlockWarnings, m.tbTimer.Timeout)
+		m.addAlert(colorCyan, "Extended time block to %s", task.TimeBlockAt.Format(m.opts.timeFormat))
+		return m.saveCurrentTask()
 	}
 
-	m.tbTimer.Model = timer.New(endTime.Sub(now))
+	end, err := parseTimeBlockEnd(arg, now)
+	if err != nil {
+		m.addAlert(colorYellow, "%s: %s", timeBlockUsage, err)
+		return nil
+	}
+	task.TimeBlockAt = end
+	m.tbTimer = newTimeBlockTimer(end.Sub(now), m.opts.timeBlockWarnings)
+	cmds := []tea.Cmd{m.tbTimer.Init(), m.saveCurrentTask()}
+	if task.IsPaused() {
+		cmds = append(cmds, m.tbTimer.Stop())
+	}
+	return tea.Batch(cmds...)
 }
 
-func (m *model) editPendingItem(edit string) *Task {
+// startPomodoro parses "[work] [break]" in minutes and starts the first work interval
+func (m *model) startPomodoro(arg string) tea.Cmd {
+	work, brk := 25*time.Minute, 5*time.Minute
+	fields := strings.Fields(arg)
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || i > 1 {
+			m.addAlert(colorYellow, "usage: /pomo [work] [break]")
+			return nil
+		}
+		if i == 0 {
+			work = time.Duration(n) * time.Minute
+		} else {
+			brk = time.Duration(n) * time.Minute
+		}
+	}
+	if !m.currentTask().IsPending() {
+		m.addAlert(colorRed, "no pending task for pomodoro")
+		return nil
+	}
+
+	m.tbTimer = newPomodoroTimer(work, brk)
+	if t := m.currentTask(); !t.TimeBlockAt.IsZero() {
+		t.TimeBlockAt = time.Time{}
+		return tea.Batch(m.tbTimer.Init(), m.saveCurrentTask())
+	}
+	return m.tbTimer.Init()
+}
+
+// advancePomodoro handles the end of a pomodoro work or break interval
+func (m model) advancePomodoro() (model, tea.Cmd) {
+	now := time.Now()
+	var cmds []tea.Cmd
+	m.tbTimer = m.tbTimer.nextInterval()
 	t := m.currentTask()
-	if n := t.LastNote(); n != nil {
-		n.Name = edit
+
+	if m.tbTimer.pomo.onBreak {
+		m.addAlert(colorCyan, "Pomodoro %d done, take a %s break", m.tbTimer.pomo.completed, formatDuration(m.tbTimer.pomo.brk))
+		cmds = append(cmds, m.notify(EventTimeBlockExpired, t, "Pomodoro %d done, take a %s break", m.tbTimer.pomo.completed, formatDuration(m.tbTimer.pomo.brk)))
+		if t.IsPending() {
+			t.Pomodoros++
+			if m.opts.pomoBreakAction == PomoBreakEnd {
+				if ended, err := m.endPendingTask(); err == nil {
+					cmds = append(cmds, m.persistEndedTask(ended))
+				}
+			} else if !t.IsPaused() {
+				t.TogglePause(now)
+				cmds = append(cmds, m.savePauses())
+			}
+		}
+		if m.opts.pomoBreakAction != PomoBreakContinue {
+			if next := m.taskQueue.Peek(); next != nil {
+				m.addAlert(colorCyan, "Up next: %s", next.Name)
+			}
+		}
 	} else {
-		t.Name = edit
-		t.Tags = extractTags(edit)
-		return t
+		m.addAlert(colorCyan, "Break over")
+		cmds = append(cmds, m.notify(EventTimeBlockExpired, t, "Break over"))
+		if t.IsPending() {
+			if t.IsPaused() {
+				t.TogglePause(now)
+				cmds = append(cmds, m.savePauses())
+			}
+		} else if m.taskQueue.Size() > 0 {
+			cmds = append(cmds, m.startTask(m.taskQueue.Dequeue()))
+		} else {
+			m.tbTimer = timeBlockTimer{}
+			m.addAlert(colorYellow, "task queue is empty, stopped pomodoro")
+		}
 	}
-	return nil
+
+	if m.tbTimer.pomo != nil {
+		cmds = append(cmds, m.tbTimer.Init())
+	}
+	m.vp.SetContent(m.renderVisibleTasks())
+	m.resizeViewport()
+	return m, tea.Batch(cmds...)
 }
 
 func (m model) handleInput(input string) (model, tea.Cmd) {
@@ -609,32 +1492,34 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 			}
 
 			var persistEnded tea.Cmd
+			var before Task
+			if t := m.currentTask(); t.IsPending() {
+				before = cloneTask(*t)
+			}
 			ended, err := m.endPendingTask()
 			if err == nil {
-				persistEnded = func() tea.Msg {
-					timeout, cancel := m.newTimeout()
-					defer cancel()
-					if _, err := m.taskSvc.UpsertTask(timeout, ended); err != nil {
-						return ErrorMsg{
-							err: err,
-						}
-					}
-					return nil
-				}
+				persistEnded = m.persistEndedTask(ended)
+			}
+			startCmd := m.startTask(started)
+			if err == nil {
+				m.recordUndo(undoEnd, before, ended.EndedAt)
 			}
-			started.StartedAt = time.Now()
-			m.taskLog = append(m.taskLog, started)
-			return m, persistEnded
+			return m, tea.Batch(persistEnded, startCmd)
 		case "/x":
 			if !m.currentTask().IsPending() {
 				m.addAlert(colorRed, "nothing left to delete")
 				return m, nil
 			}
+			before := cloneTask(*m.currentTask())
 			deleted := m.deleteLastPendingTaskItem()
+			var startCmd tea.Cmd
 			if !m.currentTask().IsPending() && m.taskQueue.Size() > 0 {
-				started := m.taskQueue.Dequeue()
-				started.StartedAt = time.Now()
-				m.taskLog = append(m.taskLog, started)
+				startCmd = m.startTask(m.taskQueue.Dequeue())
+			}
+			if before.LastNote() != nil {
+				m.recordUndo(undoDeleteNote, before, time.Now())
+			} else {
+				m.recordUndo(undoDelete, before, time.Now())
 			}
 			var cmd tea.Cmd
 			if !deleted.CreatedAt.IsZero() {
@@ -650,28 +1535,115 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 					return nil
 				}
 			}
-			return m, cmd
-		case "/h":
-			m.addAlert(colorYellow, commandHelp)
+			return m, tea.Batch(cmd, startCmd)
+		case "/sub":
+			if len(parts) < 2 {
+				m.addAlert(colorYellow, "usage: /sub <subtask>")
+				return m, nil
+			}
+			if !m.currentTask().IsPending() {
+				m.addAlert(colorRed, "no pending task to add subtask to")
+				return m, nil
+			}
+			m.addSubtask(parts[1])
 			return m, nil
-		case "/e":
+		case "/done":
 			if len(parts) < 2 {
-				m.addAlert(colorYellow, "usage: /e <edit>")
+				m.addAlert(colorYellow, "usage: /done <n>")
+				return m, nil
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil {
+				m.addAlert(colorYellow, "usage: /done <n>")
 				return m, nil
 			}
+			if !m.currentTask().IsPending() {
+				m.addAlert(colorRed, "no pending task")
+				return m, nil
+			}
+			if err := m.completeSubtask(n); err != nil {
+				m.addAlert(colorRed, "%s", err)
+			}
+			return m, nil
+		case "/p":
+			t := m.currentTask()
+			if !t.IsPending() {
+				m.addAlert(colorRed, "no pending task to pause")
+				return m, nil
+			}
+			now := time.Now()
 			var cmd tea.Cmd
-			if t := m.editPendingItem(input); t != nil && t.ID != uuid.Nil {
-				// update existing task
-				cmd = func() tea.Msg {
-					timeout, c := m.newTimeout()
-					defer c()
-					if _, err := m.taskSvc.UpsertTask(timeout, *t); err != nil {
-						return ErrorMsg{
-							err: err,
-						}
+			if t.TogglePause(now) {
+				if !m.tbTimer.Timedout() {
+					cmd = m.tbTimer.Stop()
+				}
+			} else if !m.tbTimer.Timedout() {
+				cmd = m.tbTimer.Start()
+				if !t.TimeBlockAt.IsZero() {
+					// push back the time block by the time spent paused
+					t.TimeBlockAt = now.Add(m.tbTimer.Timeout)
+					cmd = tea.Batch(cmd, m.saveCurrentTask())
+				}
+			}
+			return m, tea.Batch(cmd, m.savePauses())
+		case "/split":
+			if len(parts) < 2 {
+				m.addAlert(colorYellow, "usage: /split <follow-up>")
+				return m, nil
+			}
+			t := m.currentTask()
+			if !t.IsPending() {
+				m.addAlert(colorRed, "no pending task to split")
+				return m, nil
+			}
+			if m.isStarting(*t) {
+				// SplitTask needs the ended task's ID for the follow-up to continue it
+				m.addAlert(colorYellow, "\"%s\" is still being saved, try again", t.Name)
+				return m, nil
+			}
+			before := cloneTask(*t)
+			ended, err := m.endPendingTask()
+			if err != nil {
+				m.addAlert(colorRed, "no pending task to split")
+				return m, nil
+			}
+			cmds := []tea.Cmd{m.notify(EventTaskEnded, &ended, "Ended \"%s\"", ended.Name)}
+			if m.taskQueue.Size() > 0 {
+				cmds = append(cmds, m.startTask(m.taskQueue.Dequeue()))
+			}
+			m.recordUndo(undoEnd, before, ended.EndedAt)
+			followUp, requeueSubtasks := parts[1], m.opts.requeueSubtasks
+			cmds = append(cmds, func() tea.Msg {
+				timeout, c := m.newTimeout()
+				defer c()
+				queued, requeued, err := m.taskSvc.SplitTask(timeout, ended, followUp, requeueSubtasks)
+				if err != nil {
+					return ErrorMsg{
+						err: err,
 					}
-					return nil
 				}
+				return TaskSplitMsg{
+					followUp: queued,
+					requeued: requeued,
+				}
+			})
+			return m, tea.Batch(cmds...)
+		case "/h":
+			m.addAlert(colorYellow, commandHelp)
+			return m, nil
+		case "/e":
+			if len(parts) < 2 {
+				m.addAlert(colorYellow, editUsage)
+				return m, nil
+			}
+			item, ok := m.editTarget()
+			if !ok {
+				m.addAlert(colorRed, "no pending task to edit")
+				return m, nil
+			}
+			cmd, err := m.editItem(item, parts[1])
+			if err != nil {
+				m.addAlert(colorRed, "%s", err)
 			}
 			return m, cmd
 		case "/a":
@@ -683,7 +1655,7 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 			return m, func() tea.Msg {
 				timeout, c := m.newTimeout()
 				defer c()
-				inserted, err := m.taskSvc.UpsertTask(timeout, t)
+				inserted, err := m.taskSvc.QueueTask(timeout, t)
 				if err != nil {
 					return ErrorMsg{
 						err: err,
@@ -705,14 +1677,15 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 			}
 
 			curr := m.removeCurrentTask()
-			t := m.taskQueue.Dequeue()
-			t.StartedAt = time.Now()
-			m.taskLog = append(m.taskLog, t)
+			before := cloneTask(curr)
+			curr.TimeBlockAt = time.Time{}
+			startCmd := m.startTask(m.taskQueue.Dequeue())
+			m.recordUndo(undoSkip, before, time.Now())
 
-			return m, func() tea.Msg {
+			return m, tea.Batch(startCmd, func() tea.Msg {
 				timeout, c := m.newTimeout()
 				defer c()
-				updated, err := m.taskSvc.UpsertTask(timeout, curr)
+				updated, err := m.taskSvc.SkipTask(timeout, curr)
 				if err != nil {
 					return ErrorMsg{
 						err: err,
@@ -721,14 +1694,27 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 				return QueueMsg{
 					task: updated,
 				}
-			}
+			})
 		case "/t":
 			if len(parts) < 2 {
-				m.addAlert(colorYellow, "usage: /t <HHMM>")
+				m.addAlert(colorYellow, timeBlockUsage)
+				return m, nil
+			}
+			return m, m.timeBlockPendingTask(strings.TrimSpace(parts[1]))
+		case "/pomo":
+			arg := ""
+			if len(parts) > 1 {
+				arg = parts[1]
+			}
+			if strings.TrimSpace(arg) == "off" {
+				m.tbTimer = timeBlockTimer{}
+				if t := m.currentTask(); t.IsPending() && t.IsPaused() {
+					t.TogglePause(time.Now())
+					return m, m.savePauses()
+				}
 				return m, nil
 			}
-			m.timeBlockPendingTask(parts[1])
-			return m, m.tbTimer.Init()
+			return m, m.startPomodoro(arg)
 		case "/f":
 			if len(parts) < 2 {
 				m.taskQueue.SetFilter("")
@@ -736,6 +1722,103 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 				m.taskQueue.SetFilter(parts[1])
 			}
 			return m, nil
+		case "/recur":
+			arg := ""
+			if len(parts) > 1 {
+				arg = parts[1]
+			}
+			return m, func() tea.Msg {
+				timeout, c := m.newTimeout()
+				defer c()
+				out, err := runRecurCommand(timeout, m.taskSvc, arg)
+				if err != nil {
+					return ErrorMsg{
+						err: err,
+					}
+				}
+				return AlertMsg{
+					color: colorCyan,
+					msg:   out,
+				}
+			}
+		case "/again":
+			var query string
+			if len(parts) > 1 {
+				query = strings.TrimSpace(parts[1])
+			}
+			return m, func() tea.Msg {
+				timeout, c := m.newTimeout()
+				defer c()
+				past, err := findPastTask(timeout, m.taskSvc, query)
+				if err != nil {
+					return ErrorMsg{
+						err: err,
+					}
+				}
+				queued, err := m.taskSvc.QueueTask(timeout, TaskFromName(past.Name))
+				if err != nil {
+					return ErrorMsg{
+						err: err,
+					}
+				}
+				return QueueMsg{
+					task: queued,
+				}
+			}
+		case "/find":
+			if len(parts) < 2 {
+				m.addAlert(colorYellow, findUsage)
+				return m, nil
+			}
+			query := parts[1]
+			return m, func() tea.Msg {
+				timeout, c := m.newTimeout()
+				defer c()
+				tasks, err := m.taskSvc.SearchTasks(timeout, query, maxFindResults)
+				if err != nil {
+					return ErrorMsg{
+						err: err,
+					}
+				}
+				return AlertMsg{
+					color: colorCyan,
+					msg:   renderFindResults(query, tasks, m.opts.timeFormat),
+				}
+			}
+		case "/mode":
+			if len(parts) < 2 {
+				m.addAlert(colorCyan, "dequeue mode: %s", m.taskQueue.Strategy())
+				return m, nil
+			}
+			strategy, err := ParseDequeueStrategy(parts[1])
+			if err != nil {
+				m.addAlert(colorYellow, "usage: /mode [fifo|rr|weighted <tag>:<weight>...]: %s", err)
+				return m, nil
+			}
+			m.taskQueue.SetStrategy(strategy)
+			m.addAlert(colorCyan, "dequeue mode: %s", strategy)
+			return m, nil
+		case "/u":
+			n := 1
+			if len(parts) > 1 {
+				var err error
+				if n, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || n < 1 {
+					m.addAlert(colorYellow, undoUsage)
+					return m, nil
+				}
+			}
+			return m, m.undo(n)
+		case "/log":
+			if len(parts) < 2 {
+				m.addAlert(colorYellow, logUsage)
+				return m, nil
+			}
+			cmd, err := m.logTask(parts[1])
+			if err != nil {
+				m.addAlert(colorYellow, "%s", err)
+				return m, nil
+			}
+			return m, cmd
 		case "/o":
 			return m, func() tea.Msg {
 				return EndProgramMsg{
@@ -743,11 +1826,23 @@ func (m model) handleInput(input string) (model, tea.Cmd) {
 				}
 			}
 		}
+
+		if hhmm, ok := strings.CutPrefix(parts[0], "/n@"); ok {
+			var name string
+			if len(parts) > 1 {
+				name = strings.TrimSpace(parts[1])
+			}
+			cmd, err := m.startAsOf(name, hhmm)
+			if err != nil {
+				m.addAlert(colorRed, "%s", err)
+				return m, nil
+			}
+			return m, cmd
+		}
 	}
 
 	if !m.currentTask().IsPending() {
-		m.startNewTask(input)
-		return m, nil
+		return m, m.startTask(TaskFromName(input))
 	}
 	m.addNote(input)
 	return m, nil
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStartTask_EndedBeforePersisted(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m, startCmd := m.handleInput("/n draft slides")
+	m, endCmd := m.handleInput("/n")
+	runCmds(endCmd)
+	if len(svc.ended) != 0 {
+		t.Fatalf("want end of %q deferred, got %+v", "draft slides", svc.ended)
+	}
+
+	// act
+	m = applyCmds(m, startCmd)
+
+	// assert
+	i := slices.IndexFunc(svc.ended, func(t Task) bool { return t.Name == "draft slides" })
+	if i < 0 {
+		t.Fatalf("want %q ended, got %+v", "draft slides", svc.ended)
+	}
+	got := svc.ended[i]
+	if got.Name != "draft slides" || got.ID != m.taskLog[1].ID || got.EndedAt.IsZero() {
+		t.Fatalf("want %q ended with its ID, got %+v", "draft slides", got)
+	}
+	if len(m.starting) != 0 {
+		t.Fatalf("want no tasks starting, got %v", m.starting)
+	}
+}
+
+func TestStartTask_DeletedBeforePersisted(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m.taskQueue = NewTaskQueue(nil)
+	m, startCmd := m.handleInput("/n draft slides")
+	m, deleteCmd := m.handleInput("/x")
+	runCmds(deleteCmd)
+
+	// act
+	m = applyCmds(m, startCmd)
+
+	// assert
+	if len(svc.deleted) != 1 || svc.deleted[0] != svc.current.ID {
+		t.Fatalf("want %q deleted once persisted, got %v", "draft slides", svc.deleted)
+	}
+	if len(m.taskLog) != 1 {
+		t.Fatalf("want %q removed from log, got %+v", "draft slides", m.taskLog)
+	}
+}
+
+func TestStartTask_DiscardedOnQuitBeforePersisted(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m.h = 40
+	m, startCmd := m.handleInput("/n draft slides")
+	updated, cmd := m.Update(EndProgramMsg{discardPendingTask: true})
+	m = updated.(model)
+	if cmd != nil || m.quitting {
+		t.Fatalf("want quitting held off until %q is persisted", "draft slides")
+	}
+
+	// act
+	var msgs []tea.Msg
+	for _, msg := range runCmds(startCmd) {
+		updated, cmd = m.Update(msg)
+		m = updated.(model)
+		msgs = append(msgs, runCmds(cmd)...)
+	}
+
+	// assert
+	if !slices.ContainsFunc(msgs, func(msg tea.Msg) bool { return msg == tea.Quit() }) {
+		t.Fatalf("want quit once %q is persisted, got %v", "draft slides", msgs)
+	}
+	if len(svc.deleted) != 1 || svc.deleted[0] != svc.current.ID {
+		t.Fatalf("want %q deleted once persisted, got %v", "draft slides", svc.deleted)
+	}
+}
+
+func TestSplit_WhileStarting(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m, _ = m.handleInput("/n draft slides")
+
+	// act
+	m, cmd := m.handleInput("/split finish slides")
+	runCmds(cmd)
+
+	// assert
+	if got := m.currentTask(); got.Name != "draft slides" || !got.IsPending() {
+		t.Fatalf("want %q still in progress, got %+v", "draft slides", got)
+	}
+	if len(svc.ended) != 0 || len(svc.pending) != 0 {
+		t.Fatalf("want nothing split, got ended %+v and queued %+v", svc.ended, svc.pending)
+	}
+}
+
+func TestSplit_RecordsUndo(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+	m.opts.requeueSubtasks = true
+
+	// act
+	m, cmd := m.handleInput("/split finish report")
+	for _, msg := range runCmds(cmd) {
+		if split, ok := msg.(TaskSplitMsg); ok {
+			updated, _ := m.Update(split)
+			m = updated.(model)
+		}
+	}
+
+	// assert
+	if len(svc.ended) != 1 || svc.ended[0].ID != curr.ID || !svc.requeueSplit {
+		t.Fatalf("want %q split requeueing its subtasks, got %+v", curr.Name, svc.ended)
+	}
+	if len(m.undoLog) != 1 || m.undoLog[0].kind != undoEnd || m.undoLog[0].task.ID != curr.ID {
+		t.Fatalf("want end of %q undoable, got %+v", curr.Name, m.undoLog)
+	}
+	if m.taskQueue.Size() != 1 || m.taskQueue.Peek().ContinuesID != curr.ID {
+		t.Fatalf("want follow-up of %q queued, got %+v", curr.Name, m.taskQueue.Peek())
+	}
+}
+
+func TestPause_PersistsPauses(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+
+	// act
+	m, cmd := m.handleInput("/p")
+	for _, msg := range runCmds(cmd) {
+		if msg, ok := msg.(PausesSavedMsg); ok {
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+		}
+	}
+
+	// assert
+	if len(svc.pauses) != 1 || svc.pauses[0].StartedAt.IsZero() || !svc.pauses[0].EndedAt.IsZero() {
+		t.Fatalf("want open pause of %q saved, got %+v", curr.Name, svc.pauses)
+	}
+	if got := m.currentTask().Pauses; len(got) != 1 || got[0].ID != svc.pauses[0].ID {
+		t.Fatalf("want pause ID %d applied, got %+v", svc.pauses[0].ID, got)
+	}
+}
+
+func TestIdle_Prompts(t *testing.T) {
+	tests := []struct {
+		name        string
+		idleTimeout time.Duration
+		want        bool
+	}{
+		{name: "after timeout", idleTimeout: time.Minute, want: true},
+		{name: "disabled", idleTimeout: 0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			m, _, _ := newUndoModel(&fakeTaskSvc{})
+			m.opts.idleTimeout = tt.idleTimeout
+			m.h = 40
+			m.lastActivity = time.Now().Add(-10 * time.Minute)
+
+			// act
+			m = typeInput(m, "x")
+
+			// assert
+			if got := !m.idleSince.IsZero(); got != tt.want {
+				t.Fatalf("want prompt %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIdle_Discard(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m.h = 40
+	now := time.Now()
+	idleSince := now.Add(-10 * time.Minute)
+	blockAt := now.Add(20 * time.Minute)
+	m.currentTask().TimeBlockAt = blockAt
+	m.tbTimer = newTimeBlockTimer(20*time.Minute, nil)
+	m.idleSince = idleSince
+
+	// act
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = applyCmds(updated.(model), cmd)
+
+	// assert
+	got := m.currentTask()
+	if !got.IsPending() || len(got.Pauses) != 1 || !got.Pauses[0].StartedAt.Equal(idleSince) {
+		t.Fatalf("want idle time paused, got %+v", got.Pauses)
+	}
+	if len(svc.pauses) != 1 || got.Pauses[0].ID != svc.pauses[0].ID {
+		t.Fatalf("want idle pause saved, got %+v", svc.pauses)
+	}
+	if idle := got.Pauses[0].EndedAt.Sub(idleSince); !got.TimeBlockAt.Equal(blockAt.Add(idle)) || m.tbTimer.Timeout != 20*time.Minute+idle {
+		t.Fatalf("want time block pushed back by %v, got %v with %v left", idle, got.TimeBlockAt, m.tbTimer.Timeout)
+	}
+	if svc.current == nil || !svc.current.TimeBlockAt.Equal(got.TimeBlockAt) {
+		t.Fatalf("want time block saved, got %+v", svc.current)
+	}
+	if !m.idleSince.IsZero() {
+		t.Fatalf("want prompt cleared, got idle since %v", m.idleSince)
+	}
+}
+
+func TestIdle_End(t *testing.T) {
+	// arrange
+	m, curr, _ := newUndoModel(&fakeTaskSvc{})
+	now := time.Now()
+	idleSince := now.Add(-10 * time.Minute)
+	m.currentTask().TogglePause(now.Add(-20 * time.Minute))
+	m.tbTimer = newTimeBlockTimer(time.Hour, nil)
+	m.h = 40
+	m.idleSince = idleSince
+
+	// act
+	m = typeInput(m, "e")
+
+	// assert
+	got := m.taskLog[0]
+	if got.ID != curr.ID || !got.EndedAt.Equal(idleSince) {
+		t.Fatalf("want %q ended at %v, got %+v", curr.Name, idleSince, got)
+	}
+	if got.IsPaused() || !got.Pauses[0].EndedAt.Equal(idleSince) {
+		t.Fatalf("want pause ended at %v, got %+v", idleSince, got.Pauses)
+	}
+	if m.tbTimer.Timeout != 0 {
+		t.Fatalf("want time block cleared, got %v", m.tbTimer.Timeout)
+	}
+}
@@ -1,7 +1,15 @@
 package main
 
+import "github.com/google/uuid"
+
 type InitTaskQueueMsg struct {
 	tasks []Task
+	// current is the task left in progress by a previous run, if any
+	current *Task
+	// taskNames are past task names to suggest, most recently used first
+	taskNames []string
+	// lastEnded is the task that ended last before this run, if any
+	lastEnded *Task
 }
 
 type EndProgramMsg struct {
@@ -22,3 +30,58 @@ type SyncMsg struct {
 type QueueMsg struct {
 	task Task
 }
+
+// TaskLoggedMsg is a task done earlier that was persisted by /log
+type TaskLoggedMsg struct {
+	task Task
+}
+
+// TaskStartedMsg is a started task as persisted, or the error persisting it
+type TaskStartedMsg struct {
+	task Task
+	err  error
+}
+
+// PausesSavedMsg is a task's pauses as persisted
+type PausesSavedMsg struct {
+	taskID uuid.UUID
+	pauses []Pause
+}
+
+// TaskEditedMsg is a task as persisted after an edit given to /e of it or its
+// nth note
+type TaskEditedMsg struct {
+	// before is the task as it was when edited
+	before Task
+	task   Task
+	note   int
+	edit   string
+}
+
+type SubtasksQueuedMsg struct {
+	tasks []Task
+}
+
+// TaskSplitMsg is the follow-up queued by /split along with any subtasks
+// requeued from the ended task
+type TaskSplitMsg struct {
+	followUp Task
+	requeued []Task
+}
+
+type RecurrenceMsg struct {
+	tasksToQueue []Task
+	errs         []error
+}
+
+type AlertMsg struct {
+	color color
+	msg   string
+}
+
+// APIRequestMsg is a request from the API socket, answered on reply
+type APIRequestMsg struct {
+	method APIMethod
+	arg    string
+	reply  chan<- APIResult
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type NotifyEventType string
+
+const (
+	EventTaskStarted      NotifyEventType = "task_started"
+	EventTaskEnded        NotifyEventType = "task_ended"
+	EventTimeBlockWarning NotifyEventType = "time_block_warning"
+	EventTimeBlockExpired NotifyEventType = "time_block_expired"
+	EventSyncError        NotifyEventType = "sync_error"
+)
+
+type NotifyEvent struct {
+	Type    NotifyEventType `json:"type"`
+	Message string          `json:"message"`
+	Task    *TaskJSON       `json:"task,omitempty"`
+	Time    time.Time       `json:"time"`
+}
+
+func newNotifyEvent(typ NotifyEventType, t *Task, format string, args ...any) NotifyEvent {
+	e := NotifyEvent{
+		Type:    typ,
+		Message: fmt.Sprintf(format, args...),
+		Time:    time.Now(),
+	}
+	if t != nil {
+		j := t.JSON()
+		e.Task = &j
+	}
+	return e
+}
+
+// Notifier surfaces events outside of the program's view
+type Notifier interface {
+	Notify(context.Context, NotifyEvent) error
+}
+
+// NewNotifier returns a Notifier for a comma separated list of terminal
+// notifiers (bell, osc9) and, if cmd is provided, a shell command that receives
+// events as JSON on stdin. Returns nil if there's nothing to notify with.
+func NewNotifier(kinds, cmd string) (Notifier, error) {
+	var notifiers multiNotifier
+	for kind := range strings.SplitSeq(kinds, ",") {
+		switch kind = strings.TrimSpace(kind); kind {
+		case "":
+		case "bell":
+			notifiers = append(notifiers, terminalNotifier{w: os.Stderr})
+		case "osc9":
+			notifiers = append(notifiers, terminalNotifier{w: os.Stderr, osc9: true})
+		default:
+			return nil, fmt.Errorf("invalid notifier %q: expected bell|osc9", kind)
+		}
+	}
+	if cmd != "" {
+		notifiers = append(notifiers, cmdNotifier{cmd: cmd})
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	return notifiers, nil
+}
+
+type multiNotifier []Notifier
+
+func (n multiNotifier) Notify(ctx context.Context, e NotifyEvent) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// terminalNotifier rings the terminal bell or posts a desktop notification
+// with the OSC 9 escape sequence
+type terminalNotifier struct {
+	w    io.Writer
+	osc9 bool
+}
+
+func (n terminalNotifier) Notify(_ context.Context, e NotifyEvent) error {
+	seq := "\a"
+	if n.osc9 {
+		seq = fmt.Sprintf("\x1b]9;%s\a", stripControl(e.Message))
+	}
+	_, err := io.WriteString(n.w, seq)
+	return err
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// cmdNotifier runs a shell command with the event as JSON on stdin
+type cmdNotifier struct {
+	cmd string
+}
+
+func (n cmdNotifier) Notify(ctx context.Context, e NotifyEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notify event: %w", err)
+	}
+
+	c := exec.CommandContext(ctx, "sh", "-c", n.cmd)
+	c.Stdin = bytes.NewReader(data)
+	c.Env = append(os.Environ(), "DAYGO_EVENT="+string(e.Type))
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdNotifier(t *testing.T) {
+	// arrange
+	out := filepath.Join(t.TempDir(), "event.json")
+	n := cmdNotifier{cmd: `cat > "` + out + `" && [ "$DAYGO_EVENT" = task_started ]`}
+	task := TaskFromName("write report #work")
+
+	// act
+	err := n.Notify(context.Background(), newNotifyEvent(EventTaskStarted, &task, "Started \"%s\"", task.Name))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e NotifyEvent
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != EventTaskStarted || e.Task == nil || e.Task.Name != task.Name {
+		t.Fatalf("want %s event for %q, got %+v", EventTaskStarted, task.Name, e)
+	}
+}
+
+func TestCmdNotifierFailure(t *testing.T) {
+	n := cmdNotifier{cmd: "echo oops >&2; exit 1"}
+	err := n.Notify(context.Background(), newNotifyEvent(EventSyncError, nil, "sync failed"))
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Fatalf("want error containing command output, got %v", err)
+	}
+}
+
+func TestTerminalNotifier(t *testing.T) {
+	// arrange
+	var sb strings.Builder
+	n := terminalNotifier{w: &sb, osc9: true}
+
+	// act
+	err := n.Notify(context.Background(), newNotifyEvent(EventTimeBlockWarning, nil, "5m left\n"))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b]9;5m left\a"; sb.String() != want {
+		t.Fatalf("want %q, got %q", want, sb.String())
+	}
+}
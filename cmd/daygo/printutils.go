@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/benjamonnguyen/daygo"
 	"github.com/charmbracelet/lipgloss"
@@ -23,6 +24,8 @@ const (
 
 var faintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Bold(false)
 
+var selectedStyle = lipgloss.NewStyle().Reverse(true)
+
 func line(length int) string {
 	var sb strings.Builder
 	for range length {
@@ -38,3 +41,14 @@ func colorize(color color, s string) string {
 func formatForDisplay(task daygo.TaskRecord, format string) string {
 	return fmt.Sprintf("[%s] %s", task.StartedAt.Format(format), task.Name)
 }
+
+// formatDuration formats d to minute precision, e.g. "1h05m" or "12m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
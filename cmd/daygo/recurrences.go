@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+const recurUsage = "usage: /recur [<spec> <task> | rm <n>]; spec: " + daygo.RecurrenceSpecUsage
+
+// runRecurCommand lists, adds or removes recurrences and returns the output to display
+func runRecurCommand(ctx context.Context, taskSvc TaskSvc, arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		recurrences, err := taskSvc.GetRecurrences(ctx)
+		if err != nil {
+			return "", err
+		}
+		return formatRecurrences(recurrences), nil
+	}
+
+	if rest, ok := strings.CutPrefix(arg, "rm "); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil {
+			return "", fmt.Errorf("%s", recurUsage)
+		}
+		recurrences, err := taskSvc.GetRecurrences(ctx)
+		if err != nil {
+			return "", err
+		}
+		if n < 1 || n > len(recurrences) {
+			return "", fmt.Errorf("no recurrence #%d", n)
+		}
+		deleted, err := taskSvc.DeleteRecurrence(ctx, recurrences[n-1].ID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`Removed recurrence "%s"`, deleted.Name), nil
+	}
+
+	spec, name, err := daygo.SplitRecurrenceSpec(arg)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", recurUsage, err)
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s", recurUsage)
+	}
+	added, err := taskSvc.AddRecurrence(ctx, spec, name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`Recurring "%s" %s, next %s`, added.Name, added.Spec, added.NextAt.Format("Mon Jan 2")), nil
+}
+
+func formatRecurrences(recurrences []daygo.ExistingRecurrenceRecord) string {
+	if len(recurrences) == 0 {
+		return "no recurring tasks"
+	}
+	lines := make([]string, 0, len(recurrences))
+	for i, r := range recurrences {
+		lines = append(lines, fmt.Sprintf("%d. [%s] %s (next %s)", i+1, r.Spec, r.Name, r.NextAt.Format("Mon Jan 2")))
+	}
+	return strings.Join(lines, "\n")
+}
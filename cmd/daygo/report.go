@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+const reportUsage = "usage: daygo /report [--week|--month|--from YYYY-MM-DD [--to YYYY-MM-DD]] [--group-by tag|day]"
+
+type reportOptions struct {
+	// from and to are the first and last days to report on
+	from, to time.Time
+	groupBy  daygo.StatsGroup
+}
+
+// rangeFlags select the days to report on, defaulting to today. --week and
+// --month start on Monday and the first of the month and --to defaults to today.
+type rangeFlags struct {
+	week, month *bool
+	from, to    *string
+}
+
+func newRangeFlags(fs *flag.FlagSet) rangeFlags {
+	return rangeFlags{
+		week:  fs.Bool("week", false, ""),
+		month: fs.Bool("month", false, ""),
+		from:  fs.String("from", "", ""),
+		to:    fs.String("to", "", ""),
+	}
+}
+
+// days returns the first and last days selected
+func (f rangeFlags) days(now time.Time) (time.Time, time.Time, error) {
+	if (*f.week && *f.month) || ((*f.week || *f.month) && (*f.from != "" || *f.to != "")) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--week, --month and --from/--to are exclusive")
+	}
+
+	today := startOfDay(now)
+	from, to := today, today
+	switch {
+	case *f.week:
+		from = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case *f.month:
+		from = today.AddDate(0, 0, 1-today.Day())
+	case *f.from != "":
+		d, err := time.ParseInLocation(dateFormat, *f.from, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from: %w", err)
+		}
+		from = d
+	}
+	if *f.to != "" {
+		d, err := time.ParseInLocation(dateFormat, *f.to, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to: %w", err)
+		}
+		to = d
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--to is before --from")
+	}
+	return from, to, nil
+}
+
+func parseReportArgs(args []string, now time.Time) (reportOptions, error) {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	days := newRangeFlags(fs)
+	groupBy := fs.String("group-by", "", "")
+	if positional, err := parseArgs(fs, args); err != nil || len(positional) > 0 {
+		return reportOptions{}, fmt.Errorf("%s", reportUsage)
+	}
+
+	var opts reportOptions
+	var err error
+	if opts.from, opts.to, err = days.days(now); err != nil {
+		return reportOptions{}, err
+	}
+
+	switch *groupBy {
+	case "":
+	case "tag":
+		opts.groupBy = daygo.StatsGroupTag
+	case "day":
+		opts.groupBy = daygo.StatsGroupDay
+	default:
+		return reportOptions{}, fmt.Errorf("unknown group %q: %s", *groupBy, reportUsage)
+	}
+	return opts, nil
+}
+
+type ReportJSON struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	GroupBy string          `json:"group_by,omitempty"`
+	Total   ReportRowJSON   `json:"total"`
+	Groups  []ReportRowJSON `json:"groups,omitempty"`
+}
+
+type ReportRowJSON struct {
+	// Group is a tag, or a day as YYYY-MM-DD; empty for the total and untagged tasks
+	Group          string `json:"group,omitempty"`
+	Tasks          int    `json:"tasks"`
+	TrackedSeconds int64  `json:"tracked_seconds"`
+	AverageSeconds int64  `json:"average_seconds"`
+	Skipped        int    `json:"skipped"`
+	Discarded      int    `json:"discarded"`
+}
+
+func reportRow(s TaskStats) ReportRowJSON {
+	row := ReportRowJSON{
+		Group:          s.Group,
+		Tasks:          s.Count,
+		TrackedSeconds: int64(s.Tracked.Seconds()),
+		Skipped:        s.Skipped,
+		Discarded:      s.Discarded,
+	}
+	if s.Count > 0 {
+		row.AverageSeconds = row.TrackedSeconds / int64(s.Count)
+	}
+	return row
+}
+
+// runReport writes the time tracked and the number of tasks done, skipped and
+// discarded within the report's days to w
+func runReport(ctx context.Context, taskSvc TaskSvc, args []string, w io.Writer, asJSON bool) error {
+	opts, err := parseReportArgs(args, time.Now())
+	if err != nil {
+		return err
+	}
+
+	min, max := opts.from, opts.to.AddDate(0, 0, 1).Add(-time.Second)
+	totals, err := taskSvc.GetStats(ctx, min, max, daygo.StatsGroupNone)
+	if err != nil {
+		return err
+	}
+	r := ReportJSON{
+		From: opts.from.Format(dateFormat),
+		To:   opts.to.Format(dateFormat),
+	}
+	for _, s := range totals {
+		r.Total = reportRow(s)
+	}
+	if opts.groupBy != daygo.StatsGroupNone {
+		groups, err := taskSvc.GetStats(ctx, min, max, opts.groupBy)
+		if err != nil {
+			return err
+		}
+		r.GroupBy = map[daygo.StatsGroup]string{daygo.StatsGroupTag: "tag", daygo.StatsGroupDay: "day"}[opts.groupBy]
+		for _, s := range groups {
+			r.Groups = append(r.Groups, reportRow(s))
+		}
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	_, err = fmt.Fprintln(w, renderReport(r))
+	return err
+}
+
+func renderReport(r ReportJSON) string {
+	title := "Report for " + r.From
+	if r.To != r.From {
+		title += " to " + r.To
+	}
+
+	cells := func(label string, row ReportRowJSON) []string {
+		return []string{
+			label,
+			strconv.Itoa(row.Tasks),
+			formatDuration(time.Duration(row.TrackedSeconds) * time.Second),
+			formatDuration(time.Duration(row.AverageSeconds) * time.Second),
+			strconv.Itoa(row.Skipped),
+			strconv.Itoa(row.Discarded),
+		}
+	}
+	var rows [][]string
+	for _, g := range r.Groups {
+		label := g.Group
+		switch r.GroupBy {
+		case "tag":
+			label = "#" + label
+			if g.Group == "" {
+				label = "(untagged)"
+			}
+		case "day":
+			if d, err := time.Parse(dateFormat, g.Group); err == nil {
+				label = d.Format("Mon Jan 2")
+			}
+		}
+		rows = append(rows, cells(label, g))
+	}
+	rows = append(rows, cells("Total", r.Total))
+
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderTop(false).
+		BorderBottom(false).
+		BorderLeft(false).
+		BorderRight(false).
+		BorderColumn(false).
+		Headers(r.GroupBy, "tasks", "tracked", "average", "skipped", "discarded").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			s := cellStyle
+			if col > 0 {
+				s = s.Align(lipgloss.Right)
+			}
+			if row == table.HeaderRow || row == len(rows)-1 {
+				s = s.Bold(true)
+			}
+			return s
+		})
+	return title + "\n\n" + t.Render()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+func TestParseReportArgs(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 5, 15, 0, 0, 0, time.Local)
+	tests := []struct {
+		name        string
+		args        []string
+		wantFrom    string
+		wantTo      string
+		wantGroupBy daygo.StatsGroup
+		wantErr     bool
+	}{
+		{"defaults to today", nil, "2025-03-05", "2025-03-05", daygo.StatsGroupNone, false},
+		{"week starts on monday", []string{"--week", "--group-by", "day"}, "2025-03-03", "2025-03-05", daygo.StatsGroupDay, false},
+		{"month", []string{"--month", "--group-by=tag"}, "2025-03-01", "2025-03-05", daygo.StatsGroupTag, false},
+		{"from until today", []string{"--from", "2025-02-20"}, "2025-02-20", "2025-03-05", daygo.StatsGroupNone, false},
+		{"range", []string{"--from", "2025-02-20", "--to", "2025-02-21"}, "2025-02-20", "2025-02-21", daygo.StatsGroupNone, false},
+		{"week and range", []string{"--week", "--from", "2025-02-20"}, "", "", 0, true},
+		{"unknown group", []string{"--group-by", "project"}, "", "", 0, true},
+		{"to before from", []string{"--from", "2025-03-06"}, "", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			opts, err := parseReportArgs(tt.args, now)
+
+			// assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if got := opts.from.Format(dateFormat); got != tt.wantFrom {
+				t.Fatalf("want %s, got %s", tt.wantFrom, got)
+			}
+			if got := opts.to.Format(dateFormat); got != tt.wantTo {
+				t.Fatalf("want %s, got %s", tt.wantTo, got)
+			}
+			if opts.groupBy != tt.wantGroupBy {
+				t.Fatalf("want %v, got %v", tt.wantGroupBy, opts.groupBy)
+			}
+		})
+	}
+}
+
+func newReportStats() map[daygo.StatsGroup][]TaskStats {
+	stats := func(group string, count int, tracked time.Duration, skipped, discarded int) TaskStats {
+		return TaskStats{
+			TaskStatsRecord: daygo.TaskStatsRecord{Group: group, Count: count, Tracked: tracked},
+			Skipped:         skipped,
+			Discarded:       discarded,
+		}
+	}
+	return map[daygo.StatsGroup][]TaskStats{
+		daygo.StatsGroupNone: {stats("", 3, 2*time.Hour, 1, 1)},
+		daygo.StatsGroupTag: {
+			stats("", 1, 30*time.Minute, 0, 1),
+			stats("work", 2, 90*time.Minute, 1, 0),
+		},
+	}
+}
+
+func TestRunReport_Table(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{stats: newReportStats()}
+	var buf bytes.Buffer
+
+	// act
+	err := runReport(context.Background(), svc, []string{"--group-by", "tag"}, &buf, false)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	for _, want := range [][]string{
+		{"(untagged)", "1", "30m", "30m", "0", "1"},
+		{"#work", "2", "1h30m", "45m", "1", "0"},
+		{"Total", "3", "2h00m", "40m", "1", "1"},
+	} {
+		var found bool
+		for _, l := range lines {
+			if strings.Join(strings.Fields(l), " ") == strings.Join(want, " ") {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("want row %v in %q", want, buf.String())
+		}
+	}
+}
+
+func TestRunReport_JSON(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{stats: newReportStats()}
+	var buf bytes.Buffer
+
+	// act
+	err := runReport(context.Background(), svc, []string{"--week"}, &buf, true)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	var got ReportJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := ReportRowJSON{Tasks: 3, TrackedSeconds: 7200, AverageSeconds: 2400, Skipped: 1, Discarded: 1}
+	if got.Total != want {
+		t.Fatalf("want %+v, got %+v", want, got.Total)
+	}
+	if len(got.Groups) != 0 {
+		t.Fatalf("want no groups, got %+v", got.Groups)
+	}
+}
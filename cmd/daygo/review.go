@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// runReview renders the tasks started on the day some number of days ago
+func runReview(ctx context.Context, taskSvc TaskSvc, daysAgo int, timeFormat string) (string, error) {
+	day := startOfDay(time.Now()).AddDate(0, 0, -daysAgo)
+	tasks, err := taskSvc.GetTasksByStartTime(ctx, day, day.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Review for %s\n\n", day.Format("Mon Jan 2"))
+	if len(tasks) == 0 {
+		sb.WriteString("no tasks")
+		return sb.String(), nil
+	}
+
+	var subtasksDone, subtasksTotal int
+	var tracked, paused time.Duration
+	var threads []string
+	seenThreads := make(map[uuid.UUID]bool)
+	for _, t := range tasks {
+		t.IsTerminal = !t.EndedAt.IsZero()
+		rendered, _ := t.Render(timeFormat)
+		sb.WriteString(rendered)
+		sb.WriteRune('\n')
+		if !t.TimeBlockAt.IsZero() && !t.EndedAt.IsZero() {
+			sb.WriteString(renderTimeBlock(t, timeFormat))
+			sb.WriteRune('\n')
+		}
+
+		tracked += t.Duration()
+		paused += t.PausedDuration()
+
+		done, total := t.SubtaskProgress()
+		subtasksDone += done
+		subtasksTotal += total
+
+		thread, err := taskSvc.GetThread(ctx, t)
+		if err != nil {
+			return "", err
+		}
+		if len(thread) > 1 && !seenThreads[thread[0].ID] {
+			seenThreads[thread[0].ID] = true
+			threads = append(threads, renderThread(thread, timeFormat))
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n%d tasks, %s tracked", len(tasks), formatDuration(tracked))
+	if paused > 0 {
+		fmt.Fprintf(&sb, " (%s paused)", formatDuration(paused))
+	}
+	if subtasksTotal > 0 {
+		fmt.Fprintf(&sb, ", %d/%d subtasks completed", subtasksDone, subtasksTotal)
+	}
+	if len(threads) > 0 {
+		sb.WriteString("\n\nThreads:\n")
+		sb.WriteString(strings.Join(threads, "\n"))
+	}
+	return sb.String(), nil
+}
+
+// renderThread summarizes a chain of follow-up tasks and the total time spent across sessions
+func renderThread(thread []Task, timeFormat string) string {
+	var total time.Duration
+	var sessions []string
+	for _, t := range thread {
+		total += t.Duration()
+		switch {
+		case t.StartedAt.IsZero():
+			sessions = append(sessions, fmt.Sprintf("    [queued] %s", t.Name))
+		case t.EndedAt.IsZero():
+			sessions = append(sessions, fmt.Sprintf("    [%s] %s (in progress)", t.StartedAt.Format("Jan 2 "+timeFormat), t.Name))
+		default:
+			sessions = append(sessions, fmt.Sprintf("    [%s] %s (%s)", t.StartedAt.Format("Jan 2 "+timeFormat), t.Name, formatDuration(t.Duration())))
+		}
+	}
+	header := fmt.Sprintf("  %s: %d sessions, %s total", thread[0].Name, len(thread), formatDuration(total))
+	return header + "\n" + strings.Join(sessions, "\n")
+}
+
+// renderTimeBlock compares the planned end of a time blocked task with when it actually ended
+func renderTimeBlock(t Task, timeFormat string) string {
+	var diff string
+	switch d := t.EndedAt.Sub(t.TimeBlockAt).Round(time.Minute); {
+	case d > 0:
+		diff = fmt.Sprintf("%s over", formatDuration(d))
+	case d < 0:
+		diff = fmt.Sprintf("%s early", formatDuration(-d))
+	default:
+		diff = "on time"
+	}
+	return fmt.Sprintf(
+		"  planned end %s, ended %s (%s)",
+		t.TimeBlockAt.Format(timeFormat),
+		t.EndedAt.Format(timeFormat),
+		diff,
+	)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunReview_DayBounds(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	yesterday := startOfDay(time.Now()).AddDate(0, 0, -1)
+	for name, start := range map[string]time.Time{
+		"day before":    yesterday.Add(-time.Second),
+		"first thing":   yesterday,
+		"last thing":    yesterday.AddDate(0, 0, 1).Add(-time.Second),
+		"today's start": yesterday.AddDate(0, 0, 1),
+	} {
+		task := TaskFromName(name)
+		task.StartedAt = start
+		task.EndedAt = start.Add(30 * time.Minute)
+		if _, err := svc.EndTask(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// act
+	got, err := runReview(ctx, svc, 1, "15:04")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if want := "Review for " + yesterday.Format("Mon Jan 2"); !strings.HasPrefix(got, want) {
+		t.Fatalf("want prefix %q, got %q", want, got)
+	}
+	for _, name := range []string{"first thing", "last thing", "2 tasks, 1h00m tracked"} {
+		if !strings.Contains(got, name) {
+			t.Fatalf("want %q in review, got %q", name, got)
+		}
+	}
+	for _, name := range []string{"day before", "today's start"} {
+		if strings.Contains(got, name) {
+			t.Fatalf("want %q left out of review, got %q", name, got)
+		}
+	}
+}
+
+func TestRunReview_NoTasks(t *testing.T) {
+	// arrange
+	svc := newTestSvc(t)
+
+	// act
+	got, err := runReview(context.Background(), svc, 0, "15:04")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if !strings.HasSuffix(got, "no tasks") {
+		t.Fatalf("want no tasks, got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// StatusData is the current task as seen by /status format templates, e.g.
+//
+//	{{.Name}} {{.Elapsed}}{{with .Remaining}} ({{.}} left){{end}}
+type StatusData struct {
+	Name      string
+	Tags      []string
+	StartedAt time.Time
+	// Elapsed is the time spent on the task excluding pauses, e.g. "1h05m"
+	Elapsed string
+	Paused  bool
+	// TimeBlockAt is zero if the task isn't time blocked
+	TimeBlockAt time.Time
+	// Remaining is the time left in the time block; empty if not time blocked
+	Remaining    string
+	SubtasksDone int
+	Subtasks     int
+	Pomodoros    int
+}
+
+func newStatusData(t Task, now time.Time) StatusData {
+	done, total := t.SubtaskProgress()
+	d := StatusData{
+		Name:         t.Name,
+		Tags:         t.Tags,
+		StartedAt:    t.StartedAt,
+		Elapsed:      formatDuration(t.Elapsed(now)),
+		Paused:       t.IsPaused(),
+		TimeBlockAt:  t.TimeBlockAt,
+		SubtasksDone: done,
+		Subtasks:     total,
+		Pomodoros:    t.Pomodoros,
+	}
+	if !t.TimeBlockAt.IsZero() {
+		// the time block is extended on resume, so it doesn't run down while paused
+		if d.Paused {
+			now = t.Pauses[len(t.Pauses)-1].StartedAt
+		}
+		d.Remaining = formatDuration(max(0, t.TimeBlockAt.Sub(now)))
+	}
+	return d
+}
+
+// renderStatus renders the task with the text/template format
+func renderStatus(format string, t Task, now time.Time) (string, error) {
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, newStatusData(t, now)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
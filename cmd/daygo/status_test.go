@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderStatus(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	task := TaskFromName("write report #work")
+	task.StartedAt = now.Add(-65 * time.Minute)
+
+	blocked := task
+	blocked.TimeBlockAt = now.Add(20 * time.Minute)
+
+	paused := blocked
+	paused.Pauses = []Pause{{}}
+	paused.Pauses[0].StartedAt = now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name   string
+		format string
+		task   Task
+		want   string
+	}{
+		{"name and elapsed", "{{.Name}} {{.Elapsed}}", task, "write report #work 1h05m"},
+		{"no time block", "{{.Elapsed}}{{with .Remaining}} ({{.}} left){{end}}", task, "1h05m"},
+		{"time block", "{{.Elapsed}}{{with .Remaining}} ({{.}} left){{end}}", blocked, "1h05m (20m left)"},
+		{"paused", "{{.Elapsed}}{{if .Paused}} paused{{end}} {{.Remaining}}", paused, "55m paused 30m"},
+		{"tags", "{{range .Tags}}#{{.}}{{end}}", task, "#work"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := renderStatus(tt.format, tt.task, now)
+
+			// assert
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderStatus_InvalidFormat(t *testing.T) {
+	// act
+	_, err := renderStatus("{{.Nope}}", TaskFromName("write report"), time.Now())
+
+	// assert
+	if err == nil {
+		t.Fatalf("want error, got nil")
+	}
+}
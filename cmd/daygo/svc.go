@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Thiht/transactor"
@@ -12,41 +15,289 @@ import (
 )
 
 type TaskSvc interface {
+	// UpsertTask persists the task with its notes, subtasks and pauses. Notes are
+	// matched to the task's persisted notes by start time.
 	UpsertTask(context.Context, Task) (Task, error)
+	// EditTask persists a change to a task's name or times or its notes' names
+	// or start times; returns ErrInvalidTimes if the task would end before it
+	// starts, its notes would start outside of it or it would overlap another task
+	EditTask(context.Context, TaskEdit) (Task, error)
+	// LogTask persists an ended task with its notes after the fact; returns
+	// ErrInvalidTimes if it ends before it starts, its notes start outside of
+	// it or it overlaps another task
+	LogTask(context.Context, Task) (Task, error)
+	// SaveTaskRecord upserts only the task itself, leaving its notes, subtasks and pauses untouched
+	SaveTaskRecord(context.Context, Task) (Task, error)
+	// StartTask persists the task record as in progress
+	StartTask(context.Context, Task) (Task, error)
+	// EndTask persists the ended task with its notes, subtasks and pauses
+	EndTask(context.Context, Task) (Task, error)
+	// SavePauses upserts the task's pauses, matching unsaved ones to persisted
+	// pauses by start time
+	SavePauses(ctx context.Context, taskID uuid.UUID, pauses []Pause) ([]Pause, error)
+	// AddNote persists a note on the task, ending its previous note
+	AddNote(ctx context.Context, parentID uuid.UUID, text string) (Note, error)
+	// GetCurrentTask returns the latest task started on this device that hasn't
+	// ended with its subtasks and pauses, ending any left in progress before it;
+	// returns sqlite.ErrNotFound if there is none
+	GetCurrentTask(ctx context.Context) (Task, error)
+	// PeekCurrentTask returns the current task like GetCurrentTask without
+	// writing, leaving tasks in progress before it as they are
+	PeekCurrentTask(ctx context.Context) (Task, error)
 	DeleteTask(ctx context.Context, id uuid.UUID) ([]daygo.ExistingTaskRecord, error)
+	// Undo persists the inverse of actions taken in the task log, one step per
+	// action in the order given, in one transaction
+	Undo(ctx context.Context, steps []UndoStep) error
+	// QueueTask queues the task as of its QueuedAt, or now if it isn't set
 	QueueTask(context.Context, Task) (Task, error)
+	// SkipTask requeues the task at the back of the queue, recording the skip
+	SkipTask(context.Context, Task) (Task, error)
+	// SwitchTask ends the ended task like EndTask and starts next like
+	// StartTask in one transaction
+	SwitchTask(ctx context.Context, ended, next Task) (Task, Task, error)
+	// SkipToTask requeues the skipped task like SkipTask and starts next like
+	// StartTask in one transaction
+	SkipToTask(ctx context.Context, skipped, next Task) (Task, Task, error)
+	// ImportTask persists a task from an export with its notes, subtasks and
+	// pauses, keeping its ID if it has one; returns ErrTaskExists if the ID is taken
+	ImportTask(context.Context, Task) (Task, error)
 	GetPendingTasks(ctx context.Context) ([]Task, error)
+	// GetTasksByStartTime returns top-level tasks started within range with their notes and subtasks
+	GetTasksByStartTime(ctx context.Context, min, max time.Time) ([]Task, error)
+	// GetLastEndedTask returns the top-level task that ended last with its notes
+	// and subtasks; returns sqlite.ErrNotFound if there is none
+	GetLastEndedTask(ctx context.Context) (Task, error)
+	// GetTaskNames returns distinct past and queued task names, most recently used first
+	GetTaskNames(ctx context.Context, limit int) ([]string, error)
+	// SearchTasks returns up to limit top-level tasks whose name or notes match
+	// the query with their notes and subtasks, most relevant first
+	SearchTasks(ctx context.Context, query string, limit int) ([]Task, error)
+	// RequeueUnfinishedSubtasks moves the parent's unfinished subtasks into the queue as tasks
+	RequeueUnfinishedSubtasks(ctx context.Context, parent Task) ([]Task, error)
+	// SplitTask persists the ended task and queues a follow-up continuing it,
+	// moving over any unfinished subtasks or, if requeueSubtasks, queueing
+	// them as tasks of their own
+	SplitTask(ctx context.Context, ended Task, followUp string, requeueSubtasks bool) (Task, []Task, error)
+	// GetThread returns the chain of follow-ups containing the task, oldest first
+	GetThread(ctx context.Context, t Task) ([]Task, error)
+	// GetStats aggregates the tasks started and actions taken within range per group
+	GetStats(ctx context.Context, min, max time.Time, groupBy daygo.StatsGroup) ([]TaskStats, error)
 
 	// sync
 	GetTasksToSync(ctx context.Context, serverURL string) ([]daygo.ExistingTaskRecord, error)
 	GetLastSuccessfulSync(ctx context.Context, serverURL string) (daygo.ExistingSyncSessionRecord, error)
 	UpsertSyncSession(context.Context, int, daygo.SyncSessionRecord) (daygo.ExistingSyncSessionRecord, error)
+	// SyncTasks upserts the server's tasks that are newer than the client's.
+	// Pauses and task actions aren't synced and stay on the device they were recorded on.
 	SyncTasks(ctx context.Context, serverTasks []daygo.ExistingTaskRecord) ([]Task, []error)
+	GetRecurrencesToSync(ctx context.Context, serverURL string) ([]daygo.ExistingRecurrenceRecord, error)
+	SyncRecurrences(ctx context.Context, serverRecurrences []daygo.ExistingRecurrenceRecord) []error
+
+	// recurrences
+	AddRecurrence(ctx context.Context, spec daygo.RecurrenceSpec, name string) (daygo.ExistingRecurrenceRecord, error)
+	GetRecurrences(ctx context.Context) ([]daygo.ExistingRecurrenceRecord, error)
+	DeleteRecurrence(ctx context.Context, id uuid.UUID) (daygo.ExistingRecurrenceRecord, error)
+	// SpawnDueRecurrences queues a new instance of each due recurrence whose
+	// previous instance has ended. If claim is nil, occurrences are claimed
+	// locally. If claim fails, the occurrence isn't spawned and the error wraps
+	// ErrClaimFailed.
+	SpawnDueRecurrences(ctx context.Context, claim ClaimRecurrenceFunc) ([]Task, []error)
 }
 
+// ClaimRecurrenceFunc claims a recurrence's next occurrence so that only one
+// device spawns it. If the occurrence was already claimed, it returns false
+// and the claimant's record if known.
+type ClaimRecurrenceFunc func(context.Context, daygo.ExistingRecurrenceRecord) (daygo.ExistingRecurrenceRecord, bool, error)
+
 // impl
 type taskSvc struct {
 	logger          daygo.Logger
 	transactor      transactor.Transactor
 	taskRepo        daygo.TaskRepo
 	syncSessionRepo daygo.SyncSessionRepo
+	recurrenceRepo  daygo.RecurrenceRepo
+	pauseRepo       daygo.PauseRepo
+	taskActionRepo  daygo.TaskActionRepo
+	// hooks is nil if hooks are disabled
+	hooks *HookRunner
 }
 
-func NewTaskSvc(transactor transactor.Transactor, logger daygo.Logger, taskRepo daygo.TaskRepo, syncSessionRepo daygo.SyncSessionRepo) TaskSvc {
+func NewTaskSvc(transactor transactor.Transactor, logger daygo.Logger, taskRepo daygo.TaskRepo, syncSessionRepo daygo.SyncSessionRepo, recurrenceRepo daygo.RecurrenceRepo, pauseRepo daygo.PauseRepo, taskActionRepo daygo.TaskActionRepo, hooks *HookRunner) TaskSvc {
 	return &taskSvc{
 		logger:          logger,
 		transactor:      transactor,
 		taskRepo:        taskRepo,
 		syncSessionRepo: syncSessionRepo,
+		recurrenceRepo:  recurrenceRepo,
+		pauseRepo:       pauseRepo,
+		taskActionRepo:  taskActionRepo,
+		hooks:           hooks,
 	}
 }
 
 func (s *taskSvc) QueueTask(ctx context.Context, t Task) (Task, error) {
-	t.QueuedAt = time.Now()
+	queued, err := s.queueTask(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	s.hooks.Run(HookOnQueue, queued)
+	return queued, nil
+}
+
+// queueTask queues the task without running hooks so that callers in a
+// transaction can run them once it commits
+func (s *taskSvc) queueTask(ctx context.Context, t Task) (Task, error) {
+	if t.QueuedAt.IsZero() {
+		t.QueuedAt = time.Now()
+	}
 	t.StartedAt = time.Time{}
 	return s.UpsertTask(ctx, t)
 }
 
+func (s *taskSvc) SkipTask(ctx context.Context, t Task) (Task, error) {
+	queued, err := s.skipTask(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	s.hooks.Run(HookOnQueue, queued)
+	return queued, nil
+}
+
+func (s *taskSvc) skipTask(ctx context.Context, t Task) (Task, error) {
+	var queued Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		t.QueuedAt = time.Time{}
+		var err error
+		if queued, err = s.queueTask(ctx, t); err != nil {
+			return err
+		}
+		return s.recordAction(ctx, queued.ID, queued.Name, daygo.TaskActionSkip)
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return queued, nil
+}
+
+func (s *taskSvc) SkipToTask(ctx context.Context, skipped, next Task) (Task, Task, error) {
+	var queued, started Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if queued, err = s.skipTask(ctx, skipped); err != nil {
+			return err
+		}
+		started, err = s.SaveTaskRecord(ctx, next)
+		return err
+	})
+	if err != nil {
+		return Task{}, Task{}, err
+	}
+	s.hooks.Run(HookOnQueue, queued)
+	s.hooks.Run(HookOnStart, started)
+	return queued, started, nil
+}
+
+func (s *taskSvc) recordAction(ctx context.Context, taskID uuid.UUID, name string, action daygo.TaskAction) error {
+	_, err := s.taskActionRepo.InsertTaskAction(ctx, daygo.TaskActionRecord{
+		TaskID: taskID,
+		Name:   name,
+		Action: action,
+	})
+	return err
+}
+
+var ErrTaskExists = errors.New("task exists")
+
+func (s *taskSvc) ImportTask(ctx context.Context, t Task) (Task, error) {
+	if t.ID == uuid.Nil {
+		return s.UpsertTask(ctx, t)
+	}
+
+	var imported Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.taskRepo.GetTask(ctx, t.ID); err == nil {
+			return fmt.Errorf("%w: %s", ErrTaskExists, t.ID)
+		} else if !errors.Is(err, sqlite.ErrNotFound) {
+			return err
+		}
+		restored, err := s.taskRepo.RestoreTask(ctx, t.ExistingTaskRecord)
+		if err != nil {
+			return err
+		}
+		if err := s.createNotes(ctx, restored.ID, t.Notes); err != nil {
+			return err
+		}
+		subtasks, err := s.upsertSubtasks(ctx, restored.ID, t.Subtasks)
+		if err != nil {
+			return err
+		}
+		pauses, err := s.upsertPauses(ctx, restored.ID, t.Pauses)
+		if err != nil {
+			return err
+		}
+
+		imported = TaskFromRecord(restored)
+		imported.Notes = t.Notes
+		imported.Subtasks = subtasks
+		imported.Pauses = pauses
+		return nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return imported, nil
+}
+
+func (s *taskSvc) StartTask(ctx context.Context, t Task) (Task, error) {
+	started, err := s.SaveTaskRecord(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	s.hooks.Run(HookOnStart, started)
+	return started, nil
+}
+
+func (s *taskSvc) EndTask(ctx context.Context, t Task) (Task, error) {
+	ended, err := s.endTask(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	t.ExistingTaskRecord = ended.ExistingTaskRecord
+	s.hooks.Run(HookOnEnd, t)
+	return ended, nil
+}
+
+func (s *taskSvc) endTask(ctx context.Context, t Task) (Task, error) {
+	ended, err := s.UpsertTask(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	// notes added outside of the running program
+	if err := s.endNotes(ctx, ended.ID, t.EndedAt); err != nil {
+		return Task{}, err
+	}
+	return ended, nil
+}
+
+func (s *taskSvc) SwitchTask(ctx context.Context, ended, next Task) (Task, Task, error) {
+	var upserted, started Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if upserted, err = s.endTask(ctx, ended); err != nil {
+			return err
+		}
+		started, err = s.SaveTaskRecord(ctx, next)
+		return err
+	})
+	if err != nil {
+		return Task{}, Task{}, err
+	}
+	ended.ExistingTaskRecord = upserted.ExistingTaskRecord
+	s.hooks.Run(HookOnEnd, ended)
+	s.hooks.Run(HookOnStart, started)
+	return upserted, started, nil
+}
+
 func (s *taskSvc) SyncTasks(ctx context.Context, serverTasks []daygo.ExistingTaskRecord) ([]Task, []error) {
 	// Collect serverTaskIDs
 	serverTaskIDs := make([]any, 0, len(serverTasks))
@@ -71,7 +322,15 @@ func (s *taskSvc) SyncTasks(ctx context.Context, serverTasks []daygo.ExistingTas
 	for _, serverTask := range serverTasks {
 		clientTask, exists := clientTaskMap[serverTask.ID]
 		if !exists || serverTask.UpdatedAt.After(clientTask.UpdatedAt) {
-			u, err := s.UpsertTask(ctx, TaskFromRecord(serverTask))
+			var u Task
+			err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				var err error
+				if u, err = s.UpsertTask(ctx, TaskFromRecord(serverTask)); err != nil {
+					return err
+				}
+				// not resumed if it's in progress on another device
+				return s.taskRepo.MarkRemote(ctx, u.ID)
+			})
 			if err != nil {
 				errs = append(errs, err)
 			} else {
@@ -83,13 +342,193 @@ func (s *taskSvc) SyncTasks(ctx context.Context, serverTasks []daygo.ExistingTas
 }
 
 func (s *taskSvc) UpsertTask(ctx context.Context, t Task) (Task, error) {
+	res, err := s.upsertRecord(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+
+	// notes
+	if err := s.upsertNotes(ctx, res.ID, t.Notes); err != nil {
+		return Task{}, err
+	}
+
+	// subtasks
+	subtasks, err := s.upsertSubtasks(ctx, res.ID, t.Subtasks)
+	if err != nil {
+		return Task{}, err
+	}
+
+	// pauses
+	pauses, err := s.upsertPauses(ctx, res.ID, t.Pauses)
+	if err != nil {
+		return Task{}, err
+	}
+
+	upserted := TaskFromRecord(res)
+	upserted.Subtasks = subtasks
+	upserted.Pauses = pauses
+	return upserted, nil
+}
+
+// TaskEdit is a task as it was before and after an edit
+type TaskEdit struct {
+	Before, After Task
+}
+
+var ErrInvalidTimes = errors.New("invalid times")
+
+func (s *taskSvc) EditTask(ctx context.Context, e TaskEdit) (Task, error) {
+	var edited Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkTaskTimes(ctx, e.After); err != nil {
+			return err
+		}
+		var err error
+		edited, err = s.editTask(ctx, e)
+		return err
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return edited, nil
+}
+
+func (s *taskSvc) LogTask(ctx context.Context, t Task) (Task, error) {
+	if t.EndedAt.IsZero() {
+		return Task{}, fmt.Errorf("%w: \"%s\" must end", ErrInvalidTimes, t.Name)
+	}
+	var logged Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkTaskTimes(ctx, t); err != nil {
+			return err
+		}
+		var err error
+		logged, err = s.UpsertTask(ctx, t)
+		return err
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	logged.Notes = t.Notes
+	s.hooks.Run(HookOnEnd, logged)
+	return logged, nil
+}
+
+// editTask upserts the edited task without its subtasks and pauses, which
+// aren't edited. Notes of a pending task are persisted when it ends.
+func (s *taskSvc) editTask(ctx context.Context, e TaskEdit) (Task, error) {
+	t := e.After
+	t.Subtasks = nil
+	t.Pauses = nil
+	if t.IsPending() {
+		t.Notes = nil
+	} else if t.ID != uuid.Nil {
+		// notes moved to another start time are inserted again
+		starts := make(map[int64]bool)
+		for _, n := range t.Notes {
+			starts[n.StartedAt.Unix()] = true
+		}
+		moved := make(map[int64]bool)
+		for _, n := range e.Before.Notes {
+			if !starts[n.StartedAt.Unix()] {
+				moved[n.StartedAt.Unix()] = true
+			}
+		}
+		if len(moved) > 0 {
+			children, err := s.taskRepo.GetByParentID(ctx, t.ID)
+			if err != nil {
+				return Task{}, err
+			}
+			var ids []any
+			for _, c := range children {
+				if c.Kind == daygo.TaskKindTask && moved[c.StartedAt.Unix()] {
+					ids = append(ids, c.ID)
+				}
+			}
+			if len(ids) > 0 {
+				if _, err := s.taskRepo.DeleteTasks(ctx, ids); err != nil {
+					return Task{}, err
+				}
+			}
+		}
+	}
+	return s.UpsertTask(ctx, t)
+}
+
+// checkTaskTimes returns ErrInvalidTimes if the task ends before it starts, its
+// notes start outside of it or it overlaps another top-level task. Pending
+// tasks last until now.
+func (s *taskSvc) checkTaskTimes(ctx context.Context, t Task) error {
+	now := time.Now()
+	// timestamps are persisted in seconds
+	start, end := t.StartedAt.Truncate(time.Second), t.EndedAt.Truncate(time.Second)
+	if t.EndedAt.IsZero() {
+		end = now
+	}
+	if t.StartedAt.IsZero() || !end.After(start) {
+		return fmt.Errorf("%w: \"%s\" must end after it starts", ErrInvalidTimes, t.Name)
+	}
+	for _, n := range t.Notes {
+		if n.StartedAt.Before(t.StartedAt) || n.StartedAt.After(end) {
+			return fmt.Errorf("%w: note \"%s\" must start within \"%s\"", ErrInvalidTimes, n.Name, t.Name)
+		}
+	}
+
+	// tasks may span midnight
+	records, err := s.taskRepo.GetByStartTime(ctx, start.AddDate(0, 0, -1), end)
+	if err != nil {
+		return err
+	}
+	// other tasks that haven't ended are in progress on another device or
+	// were left in progress by a crash, so their end is unknown
+	inProgress, err := s.taskRepo.GetInProgress(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if r.ID == t.ID || r.ParentID != uuid.Nil || r.Kind != daygo.TaskKindTask {
+			continue
+		}
+		rEnd := r.EndedAt
+		if rEnd.IsZero() {
+			if len(inProgress) == 0 || r.ID != inProgress[0].ID {
+				continue
+			}
+			rEnd = now
+		}
+		if r.StartedAt.Before(end) && start.Before(rEnd) {
+			return fmt.Errorf("%w: \"%s\" overlaps \"%s\" (%s-%s)", ErrInvalidTimes, t.Name, r.Name, r.StartedAt.Format("15:04"), rEnd.Format("15:04"))
+		}
+	}
+	return nil
+}
+
+func (s *taskSvc) SaveTaskRecord(ctx context.Context, t Task) (Task, error) {
+	res, err := s.upsertRecord(ctx, t)
+	if err != nil {
+		return Task{}, err
+	}
+	return TaskFromRecord(res), nil
+}
+
+func (s *taskSvc) SavePauses(ctx context.Context, taskID uuid.UUID, pauses []Pause) ([]Pause, error) {
+	var saved []Pause
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		saved, err = s.upsertPauses(ctx, taskID, pauses)
+		return err
+	})
+	return saved, err
+}
+
+func (s *taskSvc) upsertRecord(ctx context.Context, t Task) (daygo.ExistingTaskRecord, error) {
 	var res daygo.ExistingTaskRecord
 	// update
 	if t.ID != uuid.Nil {
 		updated, err := s.taskRepo.UpdateTask(ctx, t.ID, t.TaskRecord)
 		if err != nil {
 			if !errors.Is(err, sqlite.ErrNotFound) {
-				return Task{}, err
+				return daygo.ExistingTaskRecord{}, err
 			}
 		}
 		res = updated
@@ -98,17 +537,338 @@ func (s *taskSvc) UpsertTask(ctx context.Context, t Task) (Task, error) {
 	if res.ID == uuid.Nil {
 		inserted, err := s.taskRepo.InsertTask(ctx, t.TaskRecord)
 		if err != nil {
-			return Task{}, err
+			return daygo.ExistingTaskRecord{}, err
 		}
 		res = inserted
 	}
+	return res, nil
+}
 
-	// notes
-	if err := s.createNotes(ctx, res.ID, t.Notes); err != nil {
+func (s *taskSvc) AddNote(ctx context.Context, parentID uuid.UUID, text string) (Note, error) {
+	n := daygo.TaskRecord{
+		Name:      text,
+		ParentID:  parentID,
+		StartedAt: time.Now(),
+	}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.endNotes(ctx, parentID, n.StartedAt); err != nil {
+			return err
+		}
+		_, err := s.taskRepo.InsertTask(ctx, n)
+		return err
+	})
+	if err != nil {
+		return Note{}, err
+	}
+	return Note(n), nil
+}
+
+// endNotes ends the task's persisted notes that haven't ended
+func (s *taskSvc) endNotes(ctx context.Context, parentID uuid.UUID, at time.Time) error {
+	children, err := s.taskRepo.GetByParentID(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if c.Kind != daygo.TaskKindTask || !c.EndedAt.IsZero() {
+			continue
+		}
+		c.EndedAt = at
+		if _, err := s.taskRepo.UpdateTask(ctx, c.ID, c.TaskRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *taskSvc) GetCurrentTask(ctx context.Context) (Task, error) {
+	var records []daygo.ExistingTaskRecord
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if records, err = s.taskRepo.GetInProgress(ctx); err != nil {
+			return err
+		}
+		return s.endStaleTasks(ctx, records)
+	})
+	if err != nil {
 		return Task{}, err
 	}
+	return s.currentTask(ctx, records)
+}
 
-	return TaskFromRecord(res), nil
+func (s *taskSvc) PeekCurrentTask(ctx context.Context) (Task, error) {
+	records, err := s.taskRepo.GetInProgress(ctx)
+	if err != nil {
+		return Task{}, err
+	}
+	return s.currentTask(ctx, records)
+}
+
+// currentTask loads the latest of the tasks in progress
+func (s *taskSvc) currentTask(ctx context.Context, inProgress []daygo.ExistingTaskRecord) (Task, error) {
+	if len(inProgress) == 0 {
+		return Task{}, fmt.Errorf("no current task: %w", sqlite.ErrNotFound)
+	}
+
+	t := TaskFromRecord(inProgress[0])
+	if err := s.loadChildren(ctx, &t, false); err != nil {
+		return Task{}, err
+	}
+	return t, nil
+}
+
+// endStaleTasks ends tasks left in progress before the latest started one,
+// e.g. by a crash, when the next one started
+func (s *taskSvc) endStaleTasks(ctx context.Context, inProgress []daygo.ExistingTaskRecord) error {
+	for i := 1; i < len(inProgress); i++ {
+		stale := inProgress[i]
+		stale.EndedAt = inProgress[i-1].StartedAt
+		s.logger.Warn("ending task left in progress", "id", stale.ID, "name", stale.Name)
+		if _, err := s.taskRepo.UpdateTask(ctx, stale.ID, stale.TaskRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *taskSvc) upsertPauses(ctx context.Context, taskID uuid.UUID, pauses []Pause) ([]Pause, error) {
+	existing, err := s.pauseRepo.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	upserted := make([]Pause, 0, len(pauses))
+	for _, p := range pauses {
+		p.TaskID = taskID
+		if p.ID == 0 {
+			// the pause may have been saved while the task was in progress
+			if i := slices.IndexFunc(existing, func(e daygo.ExistingPauseRecord) bool {
+				return e.StartedAt.Unix() == p.StartedAt.Unix()
+			}); i >= 0 {
+				p.ID = existing[i].ID
+			}
+		}
+
+		var res daygo.ExistingPauseRecord
+		if p.ID != 0 {
+			updated, err := s.pauseRepo.UpdatePause(ctx, p.ID, p.PauseRecord)
+			if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+				return nil, err
+			}
+			res = updated
+		}
+		if res.ID == 0 {
+			inserted, err := s.pauseRepo.InsertPause(ctx, p.PauseRecord)
+			if err != nil {
+				return nil, err
+			}
+			res = inserted
+		}
+		upserted = append(upserted, Pause(res))
+	}
+	return upserted, nil
+}
+
+func (s *taskSvc) upsertSubtasks(ctx context.Context, parentID uuid.UUID, subtasks []Subtask) ([]Subtask, error) {
+	upserted := make([]Subtask, 0, len(subtasks))
+	for _, sub := range subtasks {
+		sub.ParentID = parentID
+		sub.Kind = daygo.TaskKindSubtask
+
+		var res daygo.ExistingTaskRecord
+		if sub.ID != uuid.Nil {
+			updated, err := s.taskRepo.UpdateTask(ctx, sub.ID, sub.TaskRecord)
+			if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+				return nil, err
+			}
+			res = updated
+		}
+		if res.ID == uuid.Nil {
+			inserted, err := s.taskRepo.InsertTask(ctx, sub.TaskRecord)
+			if err != nil {
+				return nil, err
+			}
+			res = inserted
+		}
+		upserted = append(upserted, Subtask(res))
+	}
+	return upserted, nil
+}
+
+func (s *taskSvc) RequeueUnfinishedSubtasks(ctx context.Context, parent Task) ([]Task, error) {
+	queued, err := s.requeueUnfinishedSubtasks(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queued {
+		s.hooks.Run(HookOnQueue, q)
+	}
+	return queued, nil
+}
+
+func (s *taskSvc) requeueUnfinishedSubtasks(ctx context.Context, parent Task) ([]Task, error) {
+	var queued []Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, sub := range parent.Subtasks {
+			if sub.IsDone() || sub.ID == uuid.Nil {
+				continue
+			}
+			t := TaskFromRecord(daygo.ExistingTaskRecord(sub))
+			t.ParentID = uuid.Nil
+			t.Kind = daygo.TaskKindTask
+			t.Name = withTags(sub.Name, parent.Tags)
+			t.Tags = extractTags(t.Name)
+			q, err := s.queueTask(ctx, t)
+			if err != nil {
+				return err
+			}
+			queued = append(queued, q)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queued, nil
+}
+
+func (s *taskSvc) SplitTask(ctx context.Context, ended Task, followUp string, requeueSubtasks bool) (Task, []Task, error) {
+	var queued Task
+	var requeued []Task
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var done, unfinished []Subtask
+		for _, sub := range ended.Subtasks {
+			if sub.IsDone() {
+				done = append(done, sub)
+			} else {
+				unfinished = append(unfinished, sub)
+			}
+		}
+		if requeueSubtasks {
+			// unfinished subtasks stay on the ended task until they're queued
+			unfinished = nil
+		} else {
+			ended.Subtasks = done
+		}
+		upserted, err := s.UpsertTask(ctx, ended)
+		if err != nil {
+			return err
+		}
+		ended.ExistingTaskRecord = upserted.ExistingTaskRecord
+		if requeueSubtasks {
+			if requeued, err = s.requeueUnfinishedSubtasks(ctx, upserted); err != nil {
+				return err
+			}
+		}
+
+		t := TaskFromName(withTags(followUp, ended.Tags))
+		t.ContinuesID = upserted.ID
+		t.Subtasks = unfinished
+		queued, err = s.queueTask(ctx, t)
+		return err
+	})
+	if err != nil {
+		return Task{}, nil, err
+	}
+	s.hooks.Run(HookOnEnd, ended)
+	s.hooks.Run(HookOnQueue, queued)
+	for _, q := range requeued {
+		s.hooks.Run(HookOnQueue, q)
+	}
+	return queued, requeued, nil
+}
+
+func (s *taskSvc) GetThread(ctx context.Context, t Task) ([]Task, error) {
+	// walk back to the first task of the thread
+	thread := []Task{t}
+	for thread[0].ContinuesID != uuid.Nil {
+		r, err := s.taskRepo.GetTask(ctx, thread[0].ContinuesID)
+		if err != nil {
+			if errors.Is(err, sqlite.ErrNotFound) {
+				break
+			}
+			return nil, err
+		}
+		thread = append([]Task{TaskFromRecord(r)}, thread...)
+	}
+
+	// walk forward through follow-ups
+	for {
+		followUps, err := s.taskRepo.GetByContinuesID(ctx, thread[len(thread)-1].ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(followUps) == 0 {
+			break
+		}
+		thread = append(thread, TaskFromRecord(followUps[0]))
+	}
+
+	return thread, nil
+}
+
+// loadChildren populates the task's subtasks, pauses and, if withNotes, its notes
+func (s *taskSvc) loadChildren(ctx context.Context, t *Task, withNotes bool) error {
+	pauses, err := s.pauseRepo.GetByTaskID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range pauses {
+		t.Pauses = append(t.Pauses, Pause(p))
+	}
+
+	children, err := s.taskRepo.GetByParentID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		switch c.Kind {
+		case daygo.TaskKindSubtask:
+			t.Subtasks = append(t.Subtasks, Subtask(c))
+		default:
+			if withNotes {
+				t.Notes = append(t.Notes, Note(c.TaskRecord))
+			}
+		}
+	}
+	slices.SortFunc(t.Subtasks, func(a, b Subtask) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	slices.SortFunc(t.Notes, func(a, b Note) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return nil
+}
+
+// upsertNotes updates the task's persisted notes started the same second as
+// the given notes, in order, and inserts the rest
+func (s *taskSvc) upsertNotes(ctx context.Context, parentID uuid.UUID, notes []Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	children, err := s.taskRepo.GetByParentID(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	persisted := make(map[int64][]uuid.UUID)
+	for _, c := range children {
+		if c.Kind == daygo.TaskKindTask {
+			persisted[c.StartedAt.Unix()] = append(persisted[c.StartedAt.Unix()], c.ID)
+		}
+	}
+	for _, n := range notes {
+		n.ParentID = parentID
+		if ids := persisted[n.StartedAt.Unix()]; len(ids) > 0 {
+			persisted[n.StartedAt.Unix()] = ids[1:]
+			if _, err := s.taskRepo.UpdateTask(ctx, ids[0], daygo.TaskRecord(n)); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := s.taskRepo.InsertTask(ctx, daygo.TaskRecord(n)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *taskSvc) createNotes(ctx context.Context, parentID uuid.UUID, notes []Note) error {
@@ -131,28 +891,284 @@ func (s *taskSvc) GetPendingTasks(ctx context.Context) ([]Task, error) {
 
 	tasks := make([]Task, 0, len(records))
 	for _, r := range records {
-		if !r.UpdatedAt.IsZero() {
-			tasks = append(tasks, TaskFromRecord(r))
+		if !r.UpdatedAt.IsZero() && r.ParentID == uuid.Nil {
+			t := TaskFromRecord(r)
+			if err := s.loadChildren(ctx, &t, false); err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, t)
 		}
 	}
 
 	return tasks, nil
 }
 
+func (s *taskSvc) GetTasksByStartTime(ctx context.Context, min, max time.Time) ([]Task, error) {
+	records, err := s.taskRepo.GetByStartTime(ctx, min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	for _, r := range records {
+		if r.ParentID != uuid.Nil {
+			continue
+		}
+		t := TaskFromRecord(r)
+		if err := s.loadChildren(ctx, &t, true); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	slices.SortFunc(tasks, func(a, b Task) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return tasks, nil
+}
+
+func (s *taskSvc) GetLastEndedTask(ctx context.Context) (Task, error) {
+	r, err := s.taskRepo.GetLastEnded(ctx)
+	if err != nil {
+		return Task{}, err
+	}
+	t := TaskFromRecord(r)
+	if err := s.loadChildren(ctx, &t, true); err != nil {
+		return Task{}, err
+	}
+	return t, nil
+}
+
+func (s *taskSvc) GetTaskNames(ctx context.Context, limit int) ([]string, error) {
+	return s.taskRepo.GetRecentNames(ctx, limit)
+}
+
+func (s *taskSvc) SearchTasks(ctx context.Context, query string, limit int) ([]Task, error) {
+	records, err := s.taskRepo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(records))
+	for _, r := range records {
+		t := TaskFromRecord(r)
+		if err := s.loadChildren(ctx, &t, true); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// TaskStats aggregates tasks and the actions taken on them
+type TaskStats struct {
+	daygo.TaskStatsRecord
+	Skipped   int
+	Discarded int
+}
+
+func (s *taskSvc) GetStats(ctx context.Context, min, max time.Time, groupBy daygo.StatsGroup) ([]TaskStats, error) {
+	records, err := s.taskRepo.GetStats(ctx, min, max, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.taskActionRepo.CountByAction(ctx, min, max, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]TaskStats, 0, len(records))
+	byGroup := make(map[string]int, len(records))
+	for _, r := range records {
+		byGroup[r.Group] = len(stats)
+		stats = append(stats, TaskStats{TaskStatsRecord: r})
+	}
+	for _, c := range counts {
+		i, ok := byGroup[c.Group]
+		if !ok {
+			i = len(stats)
+			byGroup[c.Group] = i
+			stats = append(stats, TaskStats{TaskStatsRecord: daygo.TaskStatsRecord{Group: c.Group}})
+		}
+		switch c.Action {
+		case daygo.TaskActionSkip:
+			stats[i].Skipped += c.Count
+		case daygo.TaskActionDiscard:
+			stats[i].Discarded += c.Count
+		}
+	}
+	slices.SortFunc(stats, func(a, b TaskStats) int {
+		return strings.Compare(a.Group, b.Group)
+	})
+	return stats, nil
+}
+
 func (s *taskSvc) DeleteTask(ctx context.Context, id uuid.UUID) ([]daygo.ExistingTaskRecord, error) {
-	res, err := s.taskRepo.DeleteTasks(ctx, []any{id})
+	var res []daygo.ExistingTaskRecord
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.pauseRepo.DeleteByTaskID(ctx, id); err != nil {
+			return err
+		}
+		deleted, err := s.taskRepo.DeleteTasks(ctx, []any{id})
+		if err != nil {
+			return err
+		}
+		for _, r := range deleted {
+			if r.ParentID != uuid.Nil || r.Kind != daygo.TaskKindTask {
+				continue
+			}
+			if err := s.recordAction(ctx, r.ID, r.Name, daygo.TaskActionDiscard); err != nil {
+				return err
+			}
+		}
+		res = deleted
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	for _, r := range res {
+		s.hooks.Run(HookOnDelete, TaskFromRecord(r))
+	}
 	return res, nil
 }
 
+// UndoStep is how to persist the inverse of an action. Its fields are applied
+// in the order declared.
+type UndoStep struct {
+	// Delete are tasks started by the action, deleted without recording a discard
+	Delete []uuid.UUID
+	// Save are tasks whose records are persisted as they were before the action
+	Save []Task
+	// Edit are edits reverting tasks to as they were before the action
+	Edit []TaskEdit
+	// Restore are in-progress tasks deleted by the action to recreate with their
+	// IDs; their notes, subtasks and pauses are persisted when they end
+	Restore []Task
+	// Reopen are tasks ended by the action to persist as in progress again. Their
+	// pauses and the notes and subtasks created since they ended are removed to
+	// be persisted again when they end.
+	Reopen []Task
+	// Forget are actions recorded by the action to remove
+	Forget []daygo.TaskActionRecord
+}
+
+func (s *taskSvc) Undo(ctx context.Context, steps []UndoStep) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, step := range steps {
+			for _, id := range step.Delete {
+				if _, err := s.pauseRepo.DeleteByTaskID(ctx, id); err != nil {
+					return err
+				}
+				if _, err := s.taskRepo.DeleteTasks(ctx, []any{id}); err != nil {
+					return err
+				}
+			}
+			for _, t := range step.Save {
+				if _, err := s.upsertRecord(ctx, t); err != nil {
+					return err
+				}
+			}
+			for _, e := range step.Edit {
+				if _, err := s.editTask(ctx, e); err != nil {
+					return err
+				}
+			}
+			for _, t := range step.Restore {
+				if _, err := s.taskRepo.RestoreTask(ctx, t.ExistingTaskRecord); err != nil {
+					return err
+				}
+			}
+			for _, t := range step.Reopen {
+				if err := s.reopenTask(ctx, t); err != nil {
+					return err
+				}
+			}
+			for _, a := range step.Forget {
+				if _, err := s.taskActionRepo.DeleteLastTaskAction(ctx, a.TaskID, a.Action); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// reopenTask persists the ended task as in progress, removing what was
+// persisted with it when it ended
+func (s *taskSvc) reopenTask(ctx context.Context, t Task) error {
+	// timestamps are persisted in seconds
+	endedAt := t.EndedAt.Truncate(time.Second)
+	children, err := s.taskRepo.GetByParentID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	var ids []any
+	for _, c := range children {
+		if !c.CreatedAt.Before(endedAt) {
+			ids = append(ids, c.ID)
+		}
+	}
+	if len(ids) > 0 {
+		if _, err := s.taskRepo.DeleteTasks(ctx, ids); err != nil {
+			return err
+		}
+	}
+	if _, err := s.pauseRepo.DeleteByTaskID(ctx, t.ID); err != nil {
+		return err
+	}
+
+	t.EndedAt = time.Time{}
+	_, err = s.upsertRecord(ctx, t)
+	return err
+}
+
 func (s *taskSvc) GetTasksToSync(ctx context.Context, serverURL string) ([]daygo.ExistingTaskRecord, error) {
+	lastSync, err := s.getLastSync(ctx, serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if lastSync.ID == 0 {
+		return s.taskRepo.GetAllTasks(ctx)
+	}
+
+	return s.taskRepo.GetByUpdateTime(ctx, lastSync.CreatedAt, time.Time{})
+}
+
+func (s *taskSvc) GetRecurrencesToSync(ctx context.Context, serverURL string) ([]daygo.ExistingRecurrenceRecord, error) {
+	lastSync, err := s.getLastSync(ctx, serverURL)
+	if err != nil {
+		return nil, err
+	}
+	// deleted recurrences are included so that their deletion syncs
+	return s.recurrenceRepo.GetRecurrencesByUpdateTime(ctx, lastSync.CreatedAt, time.Time{})
+}
+
+func (s *taskSvc) SyncRecurrences(ctx context.Context, serverRecurrences []daygo.ExistingRecurrenceRecord) []error {
+	var errs []error
+	for _, serverRecurrence := range serverRecurrences {
+		clientRecurrence, err := s.recurrenceRepo.GetRecurrence(ctx, serverRecurrence.ID)
+		if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		if err == nil && !serverRecurrence.Supersedes(clientRecurrence) {
+			continue
+		}
+		if _, err := s.recurrenceRepo.SyncRecurrence(ctx, serverRecurrence); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// getLastSync returns the latest partial or successful sync session
+func (s *taskSvc) getLastSync(ctx context.Context, serverURL string) (daygo.ExistingSyncSessionRecord, error) {
 	var lastSync daygo.ExistingSyncSessionRecord
 	lastPartialSync, err := s.syncSessionRepo.GetLastSession(ctx, serverURL, daygo.SyncStatusPartial)
 	if err != nil {
 		if !errors.Is(err, sqlite.ErrNotFound) {
-			return nil, err
+			return lastSync, err
 		}
 	} else {
 		lastSync = lastPartialSync
@@ -160,16 +1176,12 @@ func (s *taskSvc) GetTasksToSync(ctx context.Context, serverURL string) ([]daygo
 	lastSuccessfulSync, err := s.syncSessionRepo.GetLastSession(ctx, serverURL, daygo.SyncStatusSuccess)
 	if err != nil {
 		if !errors.Is(err, sqlite.ErrNotFound) {
-			return nil, err
+			return lastSync, err
 		}
 	} else if lastSuccessfulSync.CreatedAt.After(lastSync.CreatedAt) {
 		lastSync = lastSuccessfulSync
 	}
-	if lastSync.ID == 0 {
-		return s.taskRepo.GetAllTasks(ctx)
-	}
-
-	return s.taskRepo.GetByUpdateTime(ctx, lastSync.CreatedAt, time.Time{})
+	return lastSync, nil
 }
 
 func (s *taskSvc) GetLastSuccessfulSync(ctx context.Context, serverURL string) (daygo.ExistingSyncSessionRecord, error) {
@@ -196,3 +1208,103 @@ func (s *taskSvc) UpsertSyncSession(ctx context.Context, id int, session daygo.S
 	}
 	return updated, nil
 }
+
+func (s *taskSvc) AddRecurrence(ctx context.Context, spec daygo.RecurrenceSpec, name string) (daygo.ExistingRecurrenceRecord, error) {
+	if name == "" {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("provide task")
+	}
+	return s.recurrenceRepo.InsertRecurrence(ctx, daygo.RecurrenceRecord{
+		Name:   name,
+		Spec:   spec.String(),
+		NextAt: spec.First(time.Now()),
+	})
+}
+
+func (s *taskSvc) GetRecurrences(ctx context.Context) ([]daygo.ExistingRecurrenceRecord, error) {
+	return s.recurrenceRepo.GetAllRecurrences(ctx)
+}
+
+func (s *taskSvc) DeleteRecurrence(ctx context.Context, id uuid.UUID) (daygo.ExistingRecurrenceRecord, error) {
+	return s.recurrenceRepo.DeleteRecurrence(ctx, id)
+}
+
+// ErrClaimFailed wraps the error claiming an occurrence on the sync server,
+// in which case the occurrence is left due to be claimed on the next check
+var ErrClaimFailed = errors.New("failed to claim recurrence")
+
+func (s *taskSvc) SpawnDueRecurrences(ctx context.Context, claim ClaimRecurrenceFunc) ([]Task, []error) {
+	now := time.Now()
+	recurrences, err := s.recurrenceRepo.GetAllRecurrences(ctx)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	pending, err := s.GetPendingTasks(ctx)
+	if err != nil {
+		return nil, []error{err}
+	}
+	inProgress, err := s.taskRepo.GetInProgress(ctx)
+	if err != nil {
+		return nil, []error{err}
+	}
+	unendedNames := make(map[string]bool, len(pending)+len(inProgress))
+	for _, t := range pending {
+		unendedNames[t.Name] = true
+	}
+	for _, t := range inProgress {
+		unendedNames[t.Name] = true
+	}
+
+	var spawned []Task
+	var errs []error
+	for _, r := range recurrences {
+		// wait for the previous instance to end before spawning the next
+		if r.NextAt.After(now) || unendedNames[r.Name] {
+			continue
+		}
+		spec, err := daygo.ParseRecurrenceSpec(r.Spec)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if claim != nil {
+			// claimed before spawning so that the sqlite write lock isn't held
+			// during the request
+			claimant, claimed, err := claim(ctx, r)
+			if err != nil {
+				// retried on the next check rather than spawned offline, which
+				// would let devices that can't reach the server all spawn it
+				errs = append(errs, fmt.Errorf("%w \"%s\": %w", ErrClaimFailed, r.Name, err))
+				continue
+			}
+			if !claimed {
+				if claimant.ID != uuid.Nil {
+					if _, err := s.recurrenceRepo.SyncRecurrence(ctx, claimant); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				continue
+			}
+		}
+
+		var queued Task
+		err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			r.NextAt = spec.Advance(r.NextAt, now)
+			if _, err := s.recurrenceRepo.UpdateRecurrence(ctx, r.ID, r.RecurrenceRecord); err != nil {
+				return err
+			}
+			var err error
+			queued, err = s.queueTask(ctx, TaskFromName(r.Name))
+			return err
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.hooks.Run(HookOnQueue, queued)
+		spawned = append(spawned, queued)
+		unendedNames[r.Name] = true
+	}
+	return spawned, errs
+}
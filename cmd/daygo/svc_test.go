@@ -0,0 +1,928 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+	"github.com/benjamonnguyen/daygo"
+	"github.com/benjamonnguyen/daygo/sqlite"
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// newTestSvc returns a task service on an in-memory database with the
+// migrations applied
+func newTestSvc(t *testing.T) *taskSvc {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each connection has its own in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	files, err := fs.Glob(migrations, "migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := fs.ReadFile(migrations, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(b)); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+	}
+
+	transactor, dbGetter := txStdLib.NewTransactor(db, txStdLib.NestedTransactionsSavepoints)
+	logger := daygo.NoOpLogger{}
+	return NewTaskSvc(
+		transactor,
+		logger,
+		sqlite.NewTaskRepo(dbGetter, logger),
+		sqlite.NewSyncSessionRepo(dbGetter, logger),
+		sqlite.NewRecurrenceRepo(dbGetter, logger),
+		sqlite.NewPauseRepo(dbGetter, logger),
+		sqlite.NewTaskActionRepo(dbGetter, logger),
+		nil,
+	).(*taskSvc)
+}
+
+// startTestTask persists a task started at the given time
+func startTestTask(t *testing.T, svc *taskSvc, name string, at time.Time) Task {
+	t.Helper()
+	task := TaskFromName(name)
+	task.StartedAt = at
+	started, err := svc.StartTask(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return started
+}
+
+func TestGetCurrentTask_EndsStaleTasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	stale := startTestTask(t, svc, "write report", start)
+	curr := startTestTask(t, svc, "review PR", start.Add(time.Hour))
+
+	// act
+	got, err := svc.GetCurrentTask(ctx)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if got.ID != curr.ID {
+		t.Fatalf("want %q, got %q", curr.Name, got.Name)
+	}
+	ended, err := svc.taskRepo.GetTask(ctx, stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ended.EndedAt.Equal(curr.StartedAt) {
+		t.Fatalf("want %q ended at %v, got %v", stale.Name, curr.StartedAt, ended.EndedAt)
+	}
+}
+
+func TestPeekCurrentTask_LeavesStaleTasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	stale := startTestTask(t, svc, "write report", start)
+	curr := startTestTask(t, svc, "review PR", start.Add(time.Hour))
+
+	// act
+	got, err := svc.PeekCurrentTask(ctx)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if got.ID != curr.ID {
+		t.Fatalf("want %q, got %q", curr.Name, got.Name)
+	}
+	left, err := svc.taskRepo.GetTask(ctx, stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !left.EndedAt.IsZero() {
+		t.Fatalf("want %q left in progress, got ended at %v", stale.Name, left.EndedAt)
+	}
+}
+
+func TestGetCurrentTask_IgnoresRemoteTasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	remote := TaskFromName("review PR")
+	remote.StartedAt = time.Now().Add(-time.Hour)
+	if _, errs := svc.SyncTasks(ctx, []daygo.ExistingTaskRecord{remote.ExistingTaskRecord}); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	// act
+	_, err := svc.GetCurrentTask(ctx)
+
+	// assert
+	if !errors.Is(err, sqlite.ErrNotFound) {
+		t.Fatalf("want %v, got %v", sqlite.ErrNotFound, err)
+	}
+}
+
+func TestLogTask_IgnoresTasksLeftInProgress(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	startTestTask(t, svc, "write report", start)
+	startTestTask(t, svc, "review PR", start.Add(2*time.Hour))
+	logged := TaskFromName("standup")
+	logged.StartedAt = start.Add(time.Hour)
+	logged.EndedAt = start.Add(90 * time.Minute)
+
+	// act
+	_, err := svc.LogTask(ctx, logged)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+}
+
+func TestSavePauses_MatchesByStartTime(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := startTestTask(t, svc, "write report", start)
+	p := Pause{}
+	p.StartedAt = start.Add(10 * time.Minute)
+	if _, err := svc.SavePauses(ctx, task.ID, []Pause{p}); err != nil {
+		t.Fatal(err)
+	}
+	p.EndedAt = start.Add(20 * time.Minute)
+
+	// act
+	_, err := svc.SavePauses(ctx, task.ID, []Pause{p})
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	got, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Pauses) != 1 || !got.Pauses[0].EndedAt.Equal(p.EndedAt) {
+		t.Fatalf("want 1 pause ended at %v, got %+v", p.EndedAt, got.Pauses)
+	}
+}
+
+func TestSavePauses_InsertsOrUpdates(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := startTestTask(t, svc, "write report", start)
+	saved, err := svc.SavePauses(ctx, task.ID, []Pause{newTestPause(start.Add(5*time.Minute), time.Time{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := saved[0]
+	updated.EndedAt = start.Add(10 * time.Minute)
+	unknown := newTestPause(start.Add(30*time.Minute), start.Add(35*time.Minute))
+	unknown.ID = updated.ID + 100
+	pauses := []Pause{
+		updated,
+		newTestPause(start.Add(20*time.Minute), start.Add(25*time.Minute)),
+		unknown,
+	}
+
+	// act
+	got, err := svc.SavePauses(ctx, task.ID, pauses)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(got) != 3 || got[0].ID != updated.ID || got[1].ID == 0 || got[2].ID == 0 || got[2].ID == unknown.ID {
+		t.Fatalf("want first pause updated and others inserted, got %+v", got)
+	}
+	persisted, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(persisted.Pauses) != 3 {
+		t.Fatalf("want 3 pauses, got %+v", persisted.Pauses)
+	}
+	for i, p := range persisted.Pauses {
+		if p.ID != got[i].ID || !p.StartedAt.Equal(pauses[i].StartedAt) || !p.EndedAt.Equal(pauses[i].EndedAt) {
+			t.Fatalf("want %+v, got %+v", got, persisted.Pauses)
+		}
+	}
+}
+
+func TestImportTask_UpdatedNow(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	created := time.Now().AddDate(0, -1, 0).Truncate(time.Second)
+	task := newPersistedTask("write report")
+	task.StartedAt = created
+	task.EndedAt = created.Add(time.Hour)
+	task.CreatedAt = created
+	task.UpdatedAt = created
+	before := time.Now().Truncate(time.Second)
+
+	// act
+	_, err := svc.ImportTask(ctx, task)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	got, err := svc.taskRepo.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(created) || got.UpdatedAt.Before(before) {
+		t.Fatalf("want created at %v and updated now, got %v and %v", created, got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func TestDeleteRecurrence_Syncs(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	added, err := svc.AddRecurrence(ctx, daygo.RecurrenceSpec{Kind: daygo.RecurrenceDaily}, "standup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := svc.DeleteRecurrence(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// claimed on another device after the delete
+	claimed := added
+	claimed.UpdatedAt = deleted.UpdatedAt.Add(time.Minute)
+
+	// act
+	errs := svc.SyncRecurrences(ctx, []daygo.ExistingRecurrenceRecord{claimed})
+
+	// assert
+	if len(errs) > 0 {
+		t.Fatalf("want no errors, got %v", errs)
+	}
+	if got, err := svc.GetRecurrences(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("want no recurrences, got %+v, %v", got, err)
+	}
+	toSync, err := svc.GetRecurrencesToSync(ctx, "http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toSync) != 1 || toSync[0].DeletedAt.IsZero() {
+		t.Fatalf("want deletion to sync, got %+v", toSync)
+	}
+	if spawned, errs := svc.SpawnDueRecurrences(ctx, nil); len(spawned) != 0 || len(errs) != 0 {
+		t.Fatalf("want nothing spawned, got %+v, %v", spawned, errs)
+	}
+}
+
+// addDueRecurrence adds a daily recurrence due today
+func addDueRecurrence(t *testing.T, svc *taskSvc, name string) daygo.ExistingRecurrenceRecord {
+	t.Helper()
+	added, err := svc.AddRecurrence(context.Background(), daygo.RecurrenceSpec{Kind: daygo.RecurrenceDaily}, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return added
+}
+
+func TestSpawnDueRecurrences_WaitsForInProgressInstance(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	addDueRecurrence(t, svc, "standup")
+	startTestTask(t, svc, "standup", time.Now().Add(-time.Minute))
+
+	// act
+	spawned, errs := svc.SpawnDueRecurrences(ctx, nil)
+
+	// assert
+	if len(spawned) != 0 || len(errs) != 0 {
+		t.Fatalf("want nothing spawned, got %+v, %v", spawned, errs)
+	}
+}
+
+func TestSpawnDueRecurrences_RetriesIfClaimFails(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	added := addDueRecurrence(t, svc, "standup")
+	claimErr := errors.New("connection refused")
+	claim := func(context.Context, daygo.ExistingRecurrenceRecord) (daygo.ExistingRecurrenceRecord, bool, error) {
+		if claimErr != nil {
+			return daygo.ExistingRecurrenceRecord{}, false, claimErr
+		}
+		return daygo.ExistingRecurrenceRecord{}, true, nil
+	}
+
+	// act
+	spawned, errs := svc.SpawnDueRecurrences(ctx, claim)
+
+	// assert
+	if len(spawned) != 0 {
+		t.Fatalf("want nothing spawned, got %+v", spawned)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrClaimFailed) {
+		t.Fatalf("want %v, got %v", ErrClaimFailed, errs)
+	}
+	got, err := svc.recurrenceRepo.GetRecurrence(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.NextAt.Equal(added.NextAt) {
+		t.Fatalf("want next at %v, got %v", added.NextAt, got.NextAt)
+	}
+
+	// act
+	claimErr = nil
+	spawned, errs = svc.SpawnDueRecurrences(ctx, claim)
+
+	// assert
+	if len(spawned) != 1 || spawned[0].Name != "standup" || len(errs) != 0 {
+		t.Fatalf("want %q spawned on retry, got %+v, %v", "standup", spawned, errs)
+	}
+}
+
+func TestSpawnDueRecurrences_SkipsIfClaimedElsewhere(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	added := addDueRecurrence(t, svc, "standup")
+	claimant := added
+	claimant.NextAt = added.NextAt.AddDate(0, 0, 1)
+	claimant.UpdatedAt = added.UpdatedAt.Add(time.Second)
+	claim := func(context.Context, daygo.ExistingRecurrenceRecord) (daygo.ExistingRecurrenceRecord, bool, error) {
+		return claimant, false, nil
+	}
+
+	// act
+	spawned, errs := svc.SpawnDueRecurrences(ctx, claim)
+
+	// assert
+	if len(spawned) != 0 || len(errs) != 0 {
+		t.Fatalf("want nothing spawned, got %+v, %v", spawned, errs)
+	}
+	if pending, err := svc.GetPendingTasks(ctx); err != nil || len(pending) != 0 {
+		t.Fatalf("want nothing queued, got %+v, %v", pending, err)
+	}
+	got, err := svc.recurrenceRepo.GetRecurrence(ctx, added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.NextAt.Equal(claimant.NextAt) {
+		t.Fatalf("want next at %v, got %v", claimant.NextAt, got.NextAt)
+	}
+}
+
+// newTestPause returns a pause of the given span, open if end is zero
+func newTestPause(start, end time.Time) Pause {
+	p := Pause{}
+	p.StartedAt = start
+	p.EndedAt = end
+	return p
+}
+
+func TestGetStats(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	base := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	report := TaskFromName("write report #work #docs")
+	report.StartedAt = base
+	report.EndedAt = base.Add(time.Hour)
+	report.Pauses = []Pause{
+		// clipped to the task's start and end
+		newTestPause(base.Add(-10*time.Minute), base.Add(10*time.Minute)),
+		newTestPause(base.Add(50*time.Minute), time.Time{}),
+	}
+	review := TaskFromName("review PR #work")
+	review.StartedAt = base.Add(2 * time.Hour)
+	review.EndedAt = base.Add(150 * time.Minute)
+	for _, task := range []Task{report, review} {
+		if _, err := svc.EndTask(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queued, err := svc.QueueTask(ctx, TaskFromName("triage #work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SkipTask(ctx, queued); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	got, err := svc.GetStats(ctx, base.Add(-time.Hour), time.Now().Add(time.Minute), daygo.StatsGroupTag)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	want := []TaskStats{
+		{TaskStatsRecord: daygo.TaskStatsRecord{Group: "docs", Count: 1, Tracked: 40 * time.Minute}},
+		{TaskStatsRecord: daygo.TaskStatsRecord{Group: "work", Count: 2, Tracked: 70 * time.Minute}, Skipped: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestUndo_ReopensTask(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	now := time.Now().Truncate(time.Second)
+	task := startTestTask(t, svc, "write report", now.Add(-time.Hour))
+	task.Notes = []Note{{Name: "outlined", StartedAt: now.Add(-30 * time.Minute), EndedAt: now}}
+	task.Pauses = []Pause{newTestPause(now.Add(-20*time.Minute), now.Add(-10*time.Minute))}
+	task.EndedAt = now
+	if _, err := svc.EndTask(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err := svc.Undo(ctx, []UndoStep{{Reopen: []Task{task}}})
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	got, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != task.ID || !got.EndedAt.IsZero() || len(got.Pauses) != 0 {
+		t.Fatalf("want %q in progress without pauses, got %+v", task.Name, got)
+	}
+	children, err := svc.taskRepo.GetByParentID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 0 {
+		t.Fatalf("want notes persisted at the end removed, got %+v", children)
+	}
+}
+
+func TestEditTask_ReplacesMovedNotes(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	before := TaskFromName("write report")
+	before.StartedAt = start
+	before.EndedAt = start.Add(time.Hour)
+	before.Notes = []Note{
+		{Name: "outlined", StartedAt: start.Add(10 * time.Minute), EndedAt: start.Add(30 * time.Minute)},
+		{Name: "drafted", StartedAt: start.Add(30 * time.Minute), EndedAt: before.EndedAt},
+	}
+	logged, err := svc.LogTask(ctx, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := cloneTask(logged)
+	after.Notes[0].EndedAt = start.Add(40 * time.Minute)
+	after.Notes[1].StartedAt = start.Add(40 * time.Minute)
+
+	// act
+	_, err = svc.EditTask(ctx, TaskEdit{Before: logged, After: after})
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	children, err := svc.taskRepo.GetByParentID(ctx, logged.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("want 2 notes, got %+v", children)
+	}
+	for _, c := range children {
+		if c.Name == "drafted" && !c.StartedAt.Equal(after.Notes[1].StartedAt) {
+			t.Fatalf("want %q moved to %v, got %v", c.Name, after.Notes[1].StartedAt, c.StartedAt)
+		}
+	}
+}
+
+func TestCheckTaskTimes(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	base := time.Now().Add(-5 * time.Hour).Truncate(time.Second)
+	ended := TaskFromName("write report")
+	ended.StartedAt = base
+	ended.EndedAt = base.Add(time.Hour)
+	if _, err := svc.EndTask(ctx, ended); err != nil {
+		t.Fatal(err)
+	}
+	startTestTask(t, svc, "review PR", base.Add(3*time.Hour))
+	newTask := func(start, end time.Duration, notes ...time.Duration) Task {
+		task := TaskFromName("standup")
+		task.StartedAt = base.Add(start)
+		task.EndedAt = base.Add(end)
+		for _, at := range notes {
+			task.Notes = append(task.Notes, Note{Name: "agenda", StartedAt: base.Add(at)})
+		}
+		return task
+	}
+	tests := []struct {
+		name    string
+		task    Task
+		wantErr bool
+	}{
+		{name: "adjacent", task: newTask(time.Hour, 2*time.Hour, 90*time.Minute)},
+		{name: "overlaps ended task", task: newTask(30*time.Minute, 90*time.Minute), wantErr: true},
+		{name: "overlaps current task", task: newTask(210*time.Minute, 4*time.Hour), wantErr: true},
+		{name: "ends before it starts", task: newTask(2*time.Hour, 90*time.Minute), wantErr: true},
+		{name: "note outside", task: newTask(time.Hour, 2*time.Hour, 150*time.Minute), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			err := svc.checkTaskTimes(ctx, tt.task)
+
+			// assert
+			if tt.wantErr != errors.Is(err, ErrInvalidTimes) {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func newTestSubtask(name string, start, end time.Time) Subtask {
+	sub := Subtask{}
+	sub.Name = name
+	sub.Kind = daygo.TaskKindSubtask
+	sub.StartedAt = start
+	sub.EndedAt = end
+	return sub
+}
+
+func TestUpsertTask_UpsertsSubtasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := TaskFromName("write report")
+	task.StartedAt = start
+	task.Subtasks = []Subtask{newTestSubtask("outline", start, time.Time{})}
+	task, err := svc.UpsertTask(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outline := task.Subtasks[0]
+	outline.EndedAt = start.Add(10 * time.Minute)
+	unknown := newTestSubtask("proofread", start, time.Time{})
+	unknown.ID = uuid.New()
+	task.Subtasks = []Subtask{outline, newTestSubtask("draft", start, time.Time{}), unknown}
+
+	// act
+	got, err := svc.UpsertTask(ctx, task)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(got.Subtasks) != 3 || got.Subtasks[0].ID != outline.ID || got.Subtasks[1].ID == uuid.Nil || got.Subtasks[2].ID == uuid.Nil {
+		t.Fatalf("want outline updated and others inserted, got %+v", got.Subtasks)
+	}
+	for _, sub := range got.Subtasks {
+		if sub.ParentID != got.ID || sub.Kind != daygo.TaskKindSubtask {
+			t.Fatalf("want subtask of %v, got %+v", got.ID, sub)
+		}
+	}
+	persisted, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done, total := persisted.SubtaskProgress(); done != 1 || total != 3 {
+		t.Fatalf("want 1/3 subtasks done, got %d/%d", done, total)
+	}
+}
+
+func TestRequeueUnfinishedSubtasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	parent := TaskFromName("write report #work")
+	parent.StartedAt = start
+	parent.EndedAt = start.Add(30 * time.Minute)
+	parent.Subtasks = []Subtask{
+		newTestSubtask("outline", start, start.Add(10*time.Minute)),
+		newTestSubtask("draft #docs", start, time.Time{}),
+		newTestSubtask("proofread #work", start, time.Time{}),
+	}
+	ended, err := svc.EndTask(ctx, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	got, err := svc.RequeueUnfinishedSubtasks(ctx, ended)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	want := []string{"draft #docs #work", "proofread #work"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %+v", want, got)
+	}
+	for i, q := range got {
+		if q.Name != want[i] || !slices.Contains(q.Tags, "work") || q.ParentID != uuid.Nil || q.Kind != daygo.TaskKindTask || !q.StartedAt.IsZero() {
+			t.Fatalf("want %q queued as a task, got %+v", want[i], q)
+		}
+		if q.ID != ended.Subtasks[i+1].ID {
+			t.Fatalf("want subtask %v requeued, got %v", ended.Subtasks[i+1].ID, q.ID)
+		}
+	}
+	pending, err := svc.GetPendingTasks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("want %v pending, got %+v", want, pending)
+	}
+	tasks, err := svc.GetTasksByStartTime(ctx, start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || len(tasks[0].Subtasks) != 1 || tasks[0].Subtasks[0].Name != "outline" {
+		t.Fatalf("want only the done subtask left on %q, got %+v", parent.Name, tasks)
+	}
+}
+
+func TestSplitTask(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := TaskFromName("write report #work")
+	task.StartedAt = start
+	task.Subtasks = []Subtask{
+		newTestSubtask("outline", start, time.Time{}),
+		newTestSubtask("draft", start, time.Time{}),
+	}
+	task, err := svc.UpsertTask(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Subtasks[0].EndedAt = start.Add(10 * time.Minute)
+	task.EndedAt = start.Add(30 * time.Minute)
+
+	// act
+	got, requeued, err := svc.SplitTask(ctx, task, "finish report", false)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(requeued) != 0 {
+		t.Fatalf("want nothing requeued, got %+v", requeued)
+	}
+	if got.Name != "finish report #work" || got.ContinuesID != task.ID || !got.StartedAt.IsZero() || got.QueuedAt.IsZero() {
+		t.Fatalf("want follow-up of %v queued, got %+v", task.ID, got)
+	}
+	if len(got.Subtasks) != 1 || got.Subtasks[0].ID != task.Subtasks[1].ID || got.Subtasks[0].ParentID != got.ID {
+		t.Fatalf("want unfinished subtask moved to follow-up, got %+v", got.Subtasks)
+	}
+	ended, err := svc.GetTasksByStartTime(ctx, start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ended) != 1 || !ended[0].EndedAt.Equal(task.EndedAt) || len(ended[0].Subtasks) != 1 || ended[0].Subtasks[0].Name != "outline" {
+		t.Fatalf("want %q ended with its done subtask, got %+v", task.Name, ended)
+	}
+	pending, err := svc.GetPendingTasks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != got.ID || len(pending[0].Subtasks) != 1 || pending[0].Subtasks[0].Name != "draft" {
+		t.Fatalf("want follow-up pending with unfinished subtask, got %+v", pending)
+	}
+}
+
+func TestSplitTask_RequeuesSubtasks(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := TaskFromName("write report #work")
+	task.StartedAt = start
+	task.Subtasks = []Subtask{
+		newTestSubtask("outline", start, time.Time{}),
+		newTestSubtask("draft", start, time.Time{}),
+	}
+	task, err := svc.UpsertTask(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Subtasks[0].EndedAt = start.Add(10 * time.Minute)
+	task.EndedAt = start.Add(30 * time.Minute)
+
+	// act
+	got, requeued, err := svc.SplitTask(ctx, task, "finish report", true)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if got.ContinuesID != task.ID || len(got.Subtasks) != 0 {
+		t.Fatalf("want follow-up of %v without subtasks, got %+v", task.ID, got)
+	}
+	if len(requeued) != 1 || requeued[0].Name != "draft #work" || requeued[0].ParentID != uuid.Nil {
+		t.Fatalf("want %q queued as a task, got %+v", "draft #work", requeued)
+	}
+	ended, err := svc.GetTasksByStartTime(ctx, start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ended) != 1 || len(ended[0].Subtasks) != 1 || ended[0].Subtasks[0].Name != "outline" {
+		t.Fatalf("want %q ended with its done subtask, got %+v", task.Name, ended)
+	}
+	pending, err := svc.GetPendingTasks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("want follow-up and requeued subtask pending, got %+v", pending)
+	}
+}
+
+func TestGetThread(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	var thread []Task
+	task := TaskFromName("draft")
+	for i, followUp := range []string{"revise", "publish"} {
+		task.StartedAt = start.Add(time.Duration(i) * time.Hour)
+		task.EndedAt = task.StartedAt.Add(30 * time.Minute)
+		ended, err := svc.EndTask(ctx, task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		thread = append(thread, ended)
+		if task, _, err = svc.SplitTask(ctx, ended, followUp, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	thread = append(thread, task)
+	startTestTask(t, svc, "unrelated", start.Add(time.Hour))
+	want := []string{"draft", "revise", "publish"}
+
+	for i, from := range thread {
+		t.Run(from.Name, func(t *testing.T) {
+			// act
+			got, err := svc.GetThread(ctx, thread[i])
+
+			// assert
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			var names []string
+			for _, task := range got {
+				names = append(names, task.Name)
+			}
+			if !slices.Equal(names, want) {
+				t.Fatalf("want %v, got %v", want, names)
+			}
+		})
+	}
+}
+
+func TestGetThread_SingleTask(t *testing.T) {
+	// arrange
+	svc := newTestSvc(t)
+	task := startTestTask(t, svc, "write report", time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	// act
+	got, err := svc.GetThread(context.Background(), task)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if len(got) != 1 || got[0].ID != task.ID {
+		t.Fatalf("want only %q, got %+v", task.Name, got)
+	}
+}
+
+func TestSwitchTask(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	now := time.Now().Truncate(time.Second)
+	curr := startTestTask(t, svc, "write report", now.Add(-time.Hour))
+	curr.EndedAt = now
+	next := TaskFromName("review PR")
+	next.StartedAt = now
+
+	// act
+	ended, started, err := svc.SwitchTask(ctx, curr, next)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if ended.ID != curr.ID || !ended.EndedAt.Equal(now) {
+		t.Fatalf("want %q ended at %v, got %+v", curr.Name, now, ended)
+	}
+	got, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != started.ID || got.Name != next.Name {
+		t.Fatalf("want %q current, got %+v", next.Name, got)
+	}
+}
+
+func TestSwitchTask_RollsBackIfStartFails(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	now := time.Now().Truncate(time.Second)
+	curr := startTestTask(t, svc, "write report", now.Add(-time.Hour))
+	curr.EndedAt = now
+	unnamed := Task{}
+	unnamed.StartedAt = now
+
+	// act
+	_, _, err := svc.SwitchTask(ctx, curr, unnamed)
+
+	// assert
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	got, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != curr.ID || !got.EndedAt.IsZero() {
+		t.Fatalf("want %q still in progress, got %+v", curr.Name, got)
+	}
+}
+
+func TestSkipToTask_RollsBackIfStartFails(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	svc := newTestSvc(t)
+	now := time.Now().Truncate(time.Second)
+	curr := startTestTask(t, svc, "write report", now.Add(-time.Hour))
+	unnamed := Task{}
+	unnamed.StartedAt = now
+
+	// act
+	_, _, err := svc.SkipToTask(ctx, curr, unnamed)
+
+	// assert
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	got, err := svc.GetCurrentTask(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != curr.ID || !got.StartedAt.Equal(curr.StartedAt) {
+		t.Fatalf("want %q still in progress, got %+v", curr.Name, got)
+	}
+	stats, err := svc.GetStats(ctx, now.Add(-time.Hour), now.Add(time.Minute), daygo.StatsGroupTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stats {
+		if s.Skipped != 0 {
+			t.Fatalf("want no skip recorded, got %+v", stats)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -12,10 +15,14 @@ type TaskQueue interface {
 	// Peek returns nil if queue is empty
 	Peek() *Task
 	Queue(t Task)
+	// Remove takes the task out of the queue; returns false if it isn't queued
+	Remove(id uuid.UUID) bool
 	Size() int
 	SetFilter(tag string)
 	FilterTag() string
 	AllTags() []string
+	SetStrategy(DequeueStrategy)
+	Strategy() DequeueStrategy
 
 	// sync
 	Sync([]Task)
@@ -24,11 +31,108 @@ type TaskQueue interface {
 type taskQueue struct {
 	filterTag    string
 	tagToTaskCnt map[string]int
+	strategy     DequeueStrategy
+	// tagCredits tracks the smooth weighted round-robin state per tag
+	tagCredits map[string]int
 
 	allTasks            []Task
 	filteredTaskIndices []int
 }
 
+type DequeueMode int
+
+const (
+	DequeueModeFIFO DequeueMode = iota
+	DequeueModeRoundRobin
+	DequeueModeWeighted
+)
+
+// DequeueStrategy determines which tag is served next. Tasks are grouped by
+// their first tag (untagged tasks form their own group) and FIFO order is
+// preserved within each group.
+type DequeueStrategy struct {
+	Mode DequeueMode
+	// Weights is only used by DequeueModeWeighted; unlisted tags have a weight of 1
+	Weights map[string]int
+}
+
+// ParseDequeueStrategy parses "fifo", "rr" or "weighted <tag>:<weight>...".
+// The "weighted" keyword may be omitted if weights are provided.
+func ParseDequeueStrategy(s string) (DequeueStrategy, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return DequeueStrategy{}, nil
+	}
+
+	switch fields[0] {
+	case "fifo":
+		return DequeueStrategy{}, nil
+	case "rr", "roundrobin":
+		return DequeueStrategy{Mode: DequeueModeRoundRobin}, nil
+	case "weighted":
+		fields = fields[1:]
+	}
+
+	weights := make(map[string]int)
+	for _, f := range fields {
+		tag, w, ok := strings.Cut(f, ":")
+		tag = strings.TrimPrefix(tag, "#")
+		if !ok || tag == "" {
+			return DequeueStrategy{}, fmt.Errorf("invalid weight %q: expected <tag>:<weight>", f)
+		}
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight < 1 {
+			return DequeueStrategy{}, fmt.Errorf("invalid weight %q: expected positive integer", f)
+		}
+		weights[tag] = weight
+	}
+	if len(weights) == 0 {
+		return DequeueStrategy{}, fmt.Errorf("weighted mode requires at least one <tag>:<weight>")
+	}
+
+	return DequeueStrategy{
+		Mode:    DequeueModeWeighted,
+		Weights: weights,
+	}, nil
+}
+
+func (s DequeueStrategy) String() string {
+	switch s.Mode {
+	case DequeueModeRoundRobin:
+		return "rr"
+	case DequeueModeWeighted:
+		tags := make([]string, 0, len(s.Weights))
+		for tag := range s.Weights {
+			tags = append(tags, tag)
+		}
+		slices.Sort(tags)
+
+		var sb strings.Builder
+		sb.WriteString("weighted")
+		for _, tag := range tags {
+			fmt.Fprintf(&sb, " %s:%d", tag, s.Weights[tag])
+		}
+		return sb.String()
+	default:
+		return "fifo"
+	}
+}
+
+func (s DequeueStrategy) weight(tag string) int {
+	if w, ok := s.Weights[tag]; ok && s.Mode == DequeueModeWeighted {
+		return w
+	}
+	return 1
+}
+
+// groupTag returns the tag used to group a task for dequeue strategies
+func groupTag(t Task) string {
+	if len(t.Tags) == 0 {
+		return ""
+	}
+	return t.Tags[0]
+}
+
 func sortTasks(a Task, b Task) int {
 	if a.QueuedAt.Before(b.QueuedAt) {
 		return 1
@@ -101,6 +205,15 @@ func (tm *taskQueue) FilterTag() string {
 	return tm.filterTag
 }
 
+func (tm *taskQueue) SetStrategy(s DequeueStrategy) {
+	tm.strategy = s
+	tm.tagCredits = nil
+}
+
+func (tm *taskQueue) Strategy() DequeueStrategy {
+	return tm.strategy
+}
+
 func (tm *taskQueue) CurrentTag() string {
 	return tm.filterTag
 }
@@ -125,10 +238,38 @@ func (tm *taskQueue) Queue(t Task) {
 	tm.filter()
 }
 
+func (tm *taskQueue) Remove(id uuid.UUID) bool {
+	i := slices.IndexFunc(tm.allTasks, func(t Task) bool {
+		return t.ID == id
+	})
+	if id == uuid.Nil || i < 0 {
+		return false
+	}
+	for _, tag := range tm.allTasks[i].Tags {
+		if tm.tagToTaskCnt[tag] == 1 {
+			delete(tm.tagToTaskCnt, tag)
+		} else {
+			tm.tagToTaskCnt[tag] -= 1
+		}
+	}
+	tm.allTasks = slices.Delete(tm.allTasks, i, i+1)
+	tm.filter()
+	return true
+}
+
 func (tm *taskQueue) Dequeue() Task {
 	task := *tm.Peek()
-	i := tm.filteredTaskIndices[len(tm.filteredTaskIndices)-1]
-	tm.filteredTaskIndices = tm.filteredTaskIndices[:len(tm.filteredTaskIndices)-1]
+	pos, credits := tm.next()
+	i := tm.filteredTaskIndices[pos]
+	tm.filteredTaskIndices = slices.Delete(tm.filteredTaskIndices, pos, pos+1)
+	for j, idx := range tm.filteredTaskIndices {
+		if idx > i {
+			tm.filteredTaskIndices[j] = idx - 1
+		}
+	}
+	if credits != nil {
+		tm.tagCredits = credits
+	}
 
 	tm.allTasks = slices.Delete(tm.allTasks, i, i+1)
 
@@ -148,6 +289,45 @@ func (tm *taskQueue) Peek() *Task {
 		return nil
 	}
 
-	i := tm.filteredTaskIndices[len(tm.filteredTaskIndices)-1]
+	pos, _ := tm.next()
+	i := tm.filteredTaskIndices[pos]
 	return &tm.allTasks[i]
 }
+
+// next returns the position in filteredTaskIndices of the task to dequeue
+// and, for non-FIFO strategies, the updated tag credits to apply on dequeue.
+// Queue must not be empty.
+func (tm *taskQueue) next() (int, map[string]int) {
+	last := len(tm.filteredTaskIndices) - 1
+	if tm.strategy.Mode == DequeueModeFIFO {
+		return last, nil
+	}
+
+	// oldest task per tag; filteredTaskIndices is ordered newest to oldest
+	tagToPos := make(map[string]int)
+	var tags []string
+	for pos := last; pos >= 0; pos-- {
+		tag := groupTag(tm.allTasks[tm.filteredTaskIndices[pos]])
+		if _, exists := tagToPos[tag]; !exists {
+			tagToPos[tag] = pos
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+
+	// smooth weighted round-robin; equal weights degrade to round-robin
+	credits := make(map[string]int, len(tags))
+	total := 0
+	selected := ""
+	for i, tag := range tags {
+		w := tm.strategy.weight(tag)
+		total += w
+		credits[tag] = tm.tagCredits[tag] + w
+		if i == 0 || credits[tag] > credits[selected] {
+			selected = tag
+		}
+	}
+	credits[selected] -= total
+
+	return tagToPos[selected], credits
+}
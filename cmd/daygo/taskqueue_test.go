@@ -0,0 +1,230 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newQueuedTask(name string, queuedAt time.Time) Task {
+	t := TaskFromName(name)
+	t.QueuedAt = queuedAt
+	return t
+}
+
+// newBurstQueue queues a burst of #work tasks followed by #home tasks
+func newBurstQueue() TaskQueue {
+	now := time.Now()
+	var tasks []Task
+	for _, name := range []string{
+		"w1 #work",
+		"w2 #work",
+		"w3 #work",
+		"w4 #work",
+		"h1 #home",
+		"h2 #home",
+	} {
+		now = now.Add(time.Minute)
+		tasks = append(tasks, newQueuedTask(name, now))
+	}
+	return NewTaskQueue(tasks)
+}
+
+func dequeueNames(tq TaskQueue, n int) []string {
+	var names []string
+	for range n {
+		names = append(names, tq.Dequeue().Name)
+	}
+	return names
+}
+
+func TestTaskQueueDequeue_FIFO(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+
+	// act
+	got := dequeueNames(tq, 6)
+
+	// assert
+	want := []string{"w1 #work", "w2 #work", "w3 #work", "w4 #work", "h1 #home", "h2 #home"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTaskQueueDequeue_RoundRobin(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+	tq.SetStrategy(DequeueStrategy{Mode: DequeueModeRoundRobin})
+
+	// act
+	got := dequeueNames(tq, 6)
+
+	// assert
+	want := []string{"h1 #home", "w1 #work", "h2 #home", "w2 #work", "w3 #work", "w4 #work"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTaskQueueDequeue_Weighted(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+	strategy, err := ParseDequeueStrategy("weighted work:3 home:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tq.SetStrategy(strategy)
+
+	// act
+	got := dequeueNames(tq, 6)
+
+	// assert
+	want := []string{"w1 #work", "h1 #home", "w2 #work", "w3 #work", "w4 #work", "h2 #home"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTaskQueueDequeue_UntaggedFormsOwnGroup(t *testing.T) {
+	// arrange
+	now := time.Now()
+	tq := NewTaskQueue([]Task{
+		newQueuedTask("w1 #work", now.Add(1*time.Minute)),
+		newQueuedTask("w2 #work", now.Add(2*time.Minute)),
+		newQueuedTask("chores", now.Add(3*time.Minute)),
+	})
+	tq.SetStrategy(DequeueStrategy{Mode: DequeueModeRoundRobin})
+
+	// act
+	got := dequeueNames(tq, 3)
+
+	// assert
+	want := []string{"chores", "w1 #work", "w2 #work"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTaskQueueDequeue_SwitchStrategyMidSession(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+
+	// act
+	got := dequeueNames(tq, 1)
+	tq.SetStrategy(DequeueStrategy{Mode: DequeueModeRoundRobin})
+	got = append(got, dequeueNames(tq, 2)...)
+	tq.SetStrategy(DequeueStrategy{})
+	got = append(got, dequeueNames(tq, 3)...)
+
+	// assert
+	want := []string{"w1 #work", "h1 #home", "w2 #work", "w3 #work", "w4 #work", "h2 #home"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if tq.Size() != 0 {
+		t.Fatalf("want empty queue, got size %d", tq.Size())
+	}
+}
+
+func TestTaskQueueDequeue_RespectsFilter(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+	tq.SetStrategy(DequeueStrategy{Mode: DequeueModeRoundRobin})
+
+	// act
+	tq.SetFilter("work")
+	got := dequeueNames(tq, 2)
+	tq.SetFilter("")
+	got = append(got, dequeueNames(tq, 2)...)
+
+	// assert
+	want := []string{"w1 #work", "w2 #work", "h1 #home", "w3 #work"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTaskQueuePeek_MatchesDequeue(t *testing.T) {
+	// arrange
+	tq := newBurstQueue()
+	strategy, err := ParseDequeueStrategy("home:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tq.SetStrategy(strategy)
+
+	for tq.Size() > 0 {
+		// act
+		peeked := tq.Peek().Name
+		dequeued := tq.Dequeue().Name
+
+		// assert
+		if peeked != dequeued {
+			t.Fatalf("peeked %s, dequeued %s", peeked, dequeued)
+		}
+	}
+}
+
+func TestTaskQueueRemove(t *testing.T) {
+	// arrange
+	now := time.Now()
+	var tasks []Task
+	for i, name := range []string{"w1 #work", "h1 #home", "w2 #work"} {
+		task := newQueuedTask(name, now.Add(time.Duration(i)*time.Minute))
+		task.ID = uuid.New()
+		tasks = append(tasks, task)
+	}
+	home := tasks[1].ID
+	tq := NewTaskQueue(tasks)
+
+	// act
+	removed := tq.Remove(home)
+
+	// assert
+	if !removed {
+		t.Fatalf("want removed, got false")
+	}
+	if got := tq.AllTags(); !slices.Equal(got, []string{"work"}) {
+		t.Fatalf("want %v, got %v", []string{"work"}, got)
+	}
+	if tq.Remove(home) {
+		t.Fatalf("want not removed twice")
+	}
+	if got := dequeueNames(tq, 2); !slices.Equal(got, []string{"w1 #work", "w2 #work"}) {
+		t.Fatalf("want %v, got %v", []string{"w1 #work", "w2 #work"}, got)
+	}
+}
+
+func TestParseDequeueStrategy(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: "fifo"},
+		{in: "fifo", want: "fifo"},
+		{in: "rr", want: "rr"},
+		{in: "weighted work:3 #home:1", want: "weighted home:1 work:3"},
+		{in: "work:2", want: "weighted work:2"},
+		{in: "weighted", wantErr: true},
+		{in: "work:0", wantErr: true},
+		{in: "work", wantErr: true},
+	} {
+		got, err := ParseDequeueStrategy(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%q: want error, got %s", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if got.String() != tc.want {
+			t.Fatalf("%q: want %s, got %s", tc.in, tc.want, got)
+		}
+	}
+}
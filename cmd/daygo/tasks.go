@@ -2,9 +2,12 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
 )
 
 // models
@@ -12,12 +15,22 @@ import (
 type Task struct {
 	daygo.ExistingTaskRecord
 	Notes      []Note
+	Subtasks   []Subtask
+	Pauses     []Pause
 	Tags       []string
 	IsTerminal bool
 }
 
 type Note daygo.TaskRecord
 
+type Subtask daygo.ExistingTaskRecord
+
+type Pause daygo.ExistingPauseRecord
+
+func (s Subtask) IsDone() bool {
+	return !s.EndedAt.IsZero()
+}
+
 func (t *Task) IsPending() bool {
 	return t != nil && !t.StartedAt.IsZero() && t.EndedAt.IsZero()
 }
@@ -29,6 +42,72 @@ func (t Task) LastNote() *Note {
 	return nil
 }
 
+// Duration returns the time spent on an ended task, excluding pauses
+func (t Task) Duration() time.Duration {
+	if t.StartedAt.IsZero() || t.EndedAt.IsZero() {
+		return 0
+	}
+	return t.EndedAt.Sub(t.StartedAt) - t.PausedDuration()
+}
+
+// Elapsed returns the time spent on the task as of now, excluding pauses
+func (t Task) Elapsed(now time.Time) time.Duration {
+	if t.EndedAt.IsZero() {
+		t.EndedAt = now
+	}
+	return t.Duration()
+}
+
+// PausedDuration returns the time paused between the task's start and end.
+// A pause without an end lasts until the task ends.
+func (t Task) PausedDuration() time.Duration {
+	var paused time.Duration
+	for _, p := range t.Pauses {
+		start := p.StartedAt
+		if start.Before(t.StartedAt) {
+			start = t.StartedAt
+		}
+		end := p.EndedAt
+		if end.IsZero() || (!t.EndedAt.IsZero() && end.After(t.EndedAt)) {
+			end = t.EndedAt
+		}
+		if end.After(start) {
+			paused += end.Sub(start)
+		}
+	}
+	return paused
+}
+
+func (t *Task) IsPaused() bool {
+	if len(t.Pauses) == 0 {
+		return false
+	}
+	return t.Pauses[len(t.Pauses)-1].EndedAt.IsZero()
+}
+
+// TogglePause pauses the task or resumes it if paused and returns whether it is now paused
+func (t *Task) TogglePause(now time.Time) bool {
+	if t.IsPaused() {
+		t.Pauses[len(t.Pauses)-1].EndedAt = now
+		return false
+	}
+	p := Pause{}
+	p.StartedAt = now
+	t.Pauses = append(t.Pauses, p)
+	return true
+}
+
+// SubtaskProgress returns the number of done and total subtasks
+func (t Task) SubtaskProgress() (int, int) {
+	done := 0
+	for _, s := range t.Subtasks {
+		if s.IsDone() {
+			done++
+		}
+	}
+	return done, len(t.Subtasks)
+}
+
 func (t Task) Render(timeFormat string) (string, int) {
 	const minLineWidth = 20
 	maxItemWidth := len(t.Name)
@@ -39,6 +118,19 @@ func (t Task) Render(timeFormat string) (string, int) {
 		}
 		notes = append(notes, note.Render(timeFormat))
 	}
+	if t.Pomodoros > 0 {
+		notes = append(notes, fmt.Sprintf("pomodoros %d", t.Pomodoros))
+	}
+	if len(t.Subtasks) > 0 {
+		done, total := t.SubtaskProgress()
+		notes = append(notes, fmt.Sprintf("subtasks %d/%d", done, total))
+		for i, s := range t.Subtasks {
+			if len(s.Name) > maxItemWidth {
+				maxItemWidth = len(s.Name)
+			}
+			notes = append(notes, s.Render(i+1))
+		}
+	}
 
 	l := maxItemWidth + 10
 	l = max(minLineWidth, l)
@@ -49,6 +141,10 @@ func (t Task) Render(timeFormat string) (string, int) {
 		taskLine,
 	}
 	lines = append(lines, notes...)
+	if t.IsPending() && t.IsPaused() {
+		pausedAt := t.Pauses[len(t.Pauses)-1].StartedAt.Format(timeFormat)
+		lines = append(lines, fmt.Sprintf("[%s] (paused)", pausedAt))
+	}
 	if t.IsTerminal {
 		endTime := t.EndedAt.Format(timeFormat)
 		lines = append(lines, fmt.Sprintf(
@@ -68,6 +164,14 @@ func (n Note) Render(timeFormat string) string {
 	return formatForDisplay(daygo.TaskRecord(n), timeFormat)
 }
 
+func (s Subtask) Render(n int) string {
+	check := ' '
+	if s.IsDone() {
+		check = 'x'
+	}
+	return fmt.Sprintf("  [%c] %d. %s", check, n, s.Name)
+}
+
 func extractTags(s string) []string {
 	var tags []string
 	for w := range strings.SplitSeq(s, " ") {
@@ -78,6 +182,17 @@ func extractTags(s string) []string {
 	return tags
 }
 
+// withTags appends any of tags missing from name
+func withTags(name string, tags []string) string {
+	existing := extractTags(name)
+	for _, tag := range tags {
+		if !slices.Contains(existing, tag) {
+			name += " #" + tag
+		}
+	}
+	return name
+}
+
 func TaskFromName(name string) Task {
 	if name == "" {
 		return Task{}
@@ -95,3 +210,111 @@ func TaskFromRecord(r daygo.ExistingTaskRecord) Task {
 	t.Tags = extractTags(r.Name)
 	return t
 }
+
+// TaskJSON is the representation of a task handed to external programs
+type TaskJSON struct {
+	ID          uuid.UUID `json:"id,omitzero"`
+	Name        string    `json:"name"`
+	Tags        []string  `json:"tags,omitempty"`
+	ContinuesID uuid.UUID `json:"continues_id,omitzero"`
+	Pomodoros   int       `json:"pomodoros,omitzero"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	EndedAt     time.Time `json:"ended_at,omitzero"`
+	QueuedAt    time.Time `json:"queued_at,omitzero"`
+	TimeBlockAt time.Time `json:"time_block_at,omitzero"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+	// DurationSeconds is the time spent on an ended task excluding pauses; ignored by TaskFromJSON
+	DurationSeconds int64         `json:"duration_seconds,omitzero"`
+	Notes           []NoteJSON    `json:"notes,omitempty"`
+	Subtasks        []SubtaskJSON `json:"subtasks,omitempty"`
+	Pauses          []PauseJSON   `json:"pauses,omitempty"`
+}
+
+type NoteJSON struct {
+	Text      string    `json:"text"`
+	StartedAt time.Time `json:"started_at,omitzero"`
+	EndedAt   time.Time `json:"ended_at,omitzero"`
+}
+
+type SubtaskJSON struct {
+	Name   string    `json:"name"`
+	DoneAt time.Time `json:"done_at,omitzero"`
+}
+
+type PauseJSON struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitzero"`
+}
+
+func (t Task) JSON() TaskJSON {
+	j := TaskJSON{
+		ID:              t.ID,
+		Name:            t.Name,
+		Tags:            t.Tags,
+		ContinuesID:     t.ContinuesID,
+		Pomodoros:       t.Pomodoros,
+		StartedAt:       t.StartedAt,
+		EndedAt:         t.EndedAt,
+		QueuedAt:        t.QueuedAt,
+		TimeBlockAt:     t.TimeBlockAt,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+		DurationSeconds: int64(t.Duration().Seconds()),
+	}
+	for _, n := range t.Notes {
+		j.Notes = append(j.Notes, NoteJSON{
+			Text:      n.Name,
+			StartedAt: n.StartedAt,
+			EndedAt:   n.EndedAt,
+		})
+	}
+	for _, s := range t.Subtasks {
+		j.Subtasks = append(j.Subtasks, SubtaskJSON{
+			Name:   s.Name,
+			DoneAt: s.EndedAt,
+		})
+	}
+	for _, p := range t.Pauses {
+		j.Pauses = append(j.Pauses, PauseJSON{
+			StartedAt: p.StartedAt,
+			EndedAt:   p.EndedAt,
+		})
+	}
+	return j
+}
+
+// TaskFromJSON is the inverse of Task.JSON; children get new IDs when persisted
+func TaskFromJSON(j TaskJSON) Task {
+	t := TaskFromName(j.Name)
+	t.ID = j.ID
+	t.ContinuesID = j.ContinuesID
+	t.Pomodoros = j.Pomodoros
+	t.StartedAt = j.StartedAt
+	t.EndedAt = j.EndedAt
+	t.QueuedAt = j.QueuedAt
+	t.TimeBlockAt = j.TimeBlockAt
+	t.CreatedAt = j.CreatedAt
+	t.UpdatedAt = j.UpdatedAt
+	for _, n := range j.Notes {
+		t.Notes = append(t.Notes, Note{
+			Name:      n.Text,
+			StartedAt: n.StartedAt,
+			EndedAt:   n.EndedAt,
+		})
+	}
+	for _, s := range j.Subtasks {
+		sub := Subtask{}
+		sub.Name = s.Name
+		sub.Kind = daygo.TaskKindSubtask
+		sub.EndedAt = s.DoneAt
+		t.Subtasks = append(t.Subtasks, sub)
+	}
+	for _, p := range j.Pauses {
+		pause := Pause{}
+		pause.StartedAt = p.StartedAt
+		pause.EndedAt = p.EndedAt
+		t.Pauses = append(t.Pauses, pause)
+	}
+	return t
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTogglePause(t *testing.T) {
+	// arrange
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	task := TaskFromName("write report")
+	task.StartedAt = start
+
+	// act
+	paused := task.TogglePause(start.Add(10 * time.Minute))
+	isPaused := task.IsPaused()
+	resumed := !task.TogglePause(start.Add(20 * time.Minute))
+
+	// assert
+	if !paused || !isPaused {
+		t.Fatalf("want paused, got toggled %v and paused %v", paused, isPaused)
+	}
+	if !resumed || task.IsPaused() {
+		t.Fatalf("want resumed, got %+v", task.Pauses)
+	}
+	want := newTestPause(start.Add(10*time.Minute), start.Add(20*time.Minute))
+	if len(task.Pauses) != 1 || task.Pauses[0] != want {
+		t.Fatalf("want %+v, got %+v", []Pause{want}, task.Pauses)
+	}
+}
+
+func TestIsPaused(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		pauses []Pause
+		want   bool
+	}{
+		{name: "no pauses", want: false},
+		{
+			name:   "resumed",
+			pauses: []Pause{newTestPause(start, start.Add(time.Minute))},
+			want:   false,
+		},
+		{
+			name:   "open pause",
+			pauses: []Pause{newTestPause(start, start.Add(time.Minute)), newTestPause(start.Add(2*time.Minute), time.Time{})},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			task := TaskFromName("write report")
+			task.Pauses = tt.pauses
+
+			// act
+			got := task.IsPaused()
+
+			// assert
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPausedDuration(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+	tests := []struct {
+		name   string
+		ended  bool
+		pauses []Pause
+		want   time.Duration
+	}{
+		{name: "no pauses", ended: true, want: 0},
+		{
+			name:   "within task",
+			ended:  true,
+			pauses: []Pause{newTestPause(start.Add(10*time.Minute), start.Add(20*time.Minute)), newTestPause(start.Add(30*time.Minute), start.Add(35*time.Minute))},
+			want:   15 * time.Minute,
+		},
+		{
+			name:   "clipped to start",
+			ended:  true,
+			pauses: []Pause{newTestPause(start.Add(-10*time.Minute), start.Add(5*time.Minute))},
+			want:   5 * time.Minute,
+		},
+		{
+			name:   "clipped to end",
+			ended:  true,
+			pauses: []Pause{newTestPause(end.Add(-5*time.Minute), end.Add(10*time.Minute))},
+			want:   5 * time.Minute,
+		},
+		{
+			name:   "open pause ends with task",
+			ended:  true,
+			pauses: []Pause{newTestPause(end.Add(-5*time.Minute), time.Time{})},
+			want:   5 * time.Minute,
+		},
+		{
+			name:   "outside task",
+			ended:  true,
+			pauses: []Pause{newTestPause(end.Add(time.Minute), end.Add(2*time.Minute))},
+			want:   0,
+		},
+		{
+			name:   "open pause of pending task",
+			pauses: []Pause{newTestPause(start.Add(10*time.Minute), time.Time{})},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			task := TaskFromName("write report")
+			task.StartedAt = start
+			if tt.ended {
+				task.EndedAt = end
+			}
+			task.Pauses = tt.pauses
+
+			// act
+			got := task.PausedDuration()
+
+			// assert
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
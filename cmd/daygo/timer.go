@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/timer"
@@ -9,6 +12,158 @@ import (
 
 type timeBlockTimer struct {
 	timer.Model
+	// pomo is set while cycling pomodoro work/break intervals
+	pomo *pomodoro
+	// warnings are the remaining times left at which to warn before the time
+	// block expires, longest first
+	warnings []time.Duration
+}
+
+const timeBlockUsage = "usage: /t <HHMM|duration|+duration|off>"
+
+var (
+	timeRe         = regexp.MustCompile(`^(?:[01]\d|2[0-3])[0-5]\d$`)
+	hourMinRe      = regexp.MustCompile(`^\d+h\d+$`)
+	errNoTimeBlock = fmt.Errorf("no time block to extend")
+)
+
+func newTimeBlockTimer(timeout time.Duration, warnings []time.Duration) timeBlockTimer {
+	t := timeBlockTimer{
+		Model: timer.New(timeout),
+	}
+	t.warnings = pendingWarnings(warnings, timeout)
+	return t
+}
+
+// pendingWarnings returns the warnings that have yet to be reached with
+// timeout left, longest first
+func pendingWarnings(warnings []time.Duration, timeout time.Duration) []time.Duration {
+	var pending []time.Duration
+	for _, w := range warnings {
+		if w < timeout {
+			pending = append(pending, w)
+		}
+	}
+	slices.SortFunc(pending, func(a, b time.Duration) int {
+		return int(b - a)
+	})
+	return pending
+}
+
+// popWarning returns the warning reached by the timer if any
+func (t *timeBlockTimer) popWarning() (time.Duration, bool) {
+	if t.pomo != nil || len(t.warnings) == 0 || t.Timedout() || t.Timeout > t.warnings[0] {
+		return 0, false
+	}
+	var w time.Duration
+	// skip past any other warnings reached in the same tick
+	for len(t.warnings) > 0 && t.Timeout <= t.warnings[0] {
+		w, t.warnings = t.warnings[0], t.warnings[1:]
+	}
+	return w, true
+}
+
+// parseTimeBlockEnd parses a 24h HHMM time, rolling over to tomorrow if it has
+// passed, or a duration from now such as 25m or 1h30
+func parseTimeBlockEnd(arg string, now time.Time) (time.Time, error) {
+	if timeRe.MatchString(arg) {
+		hhmm, _ := time.Parse("1504", arg)
+		end := time.Date(now.Year(), now.Month(), now.Day(), hhmm.Hour(), hhmm.Minute(), 0, 0, now.Location())
+		if !end.After(now) {
+			end = end.AddDate(0, 0, 1)
+		}
+		return end, nil
+	}
+
+	d, err := parseTimeBlockDuration(arg)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(d), nil
+}
+
+// parseTimeBlockDuration parses a positive duration, allowing the minutes unit
+// to be omitted after hours (1h30)
+func parseTimeBlockDuration(s string) (time.Duration, error) {
+	if hourMinRe.MatchString(s) {
+		s += "m"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time block %q", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("time block must be positive: %s", s)
+	}
+	return d, nil
+}
+
+// ParseTimeBlockWarnings parses a comma separated list of durations before a
+// time block expires to warn at
+func ParseTimeBlockWarnings(s string) ([]time.Duration, error) {
+	var warnings []time.Duration
+	for f := range strings.SplitSeq(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid time block warning %q", f)
+		}
+		warnings = append(warnings, d)
+	}
+	return warnings, nil
+}
+
+type pomodoro struct {
+	work      time.Duration
+	brk       time.Duration
+	onBreak   bool
+	completed int
+}
+
+type PomoBreakAction string
+
+const (
+	PomoBreakContinue PomoBreakAction = "continue" // pause current task during break
+	PomoBreakSurface  PomoBreakAction = "surface"  // pause current task and show the next queued task
+	PomoBreakEnd      PomoBreakAction = "end"      // end current task and start the next queued task after break
+)
+
+func ParsePomoBreakAction(s string) (PomoBreakAction, error) {
+	switch a := PomoBreakAction(s); a {
+	case PomoBreakContinue, PomoBreakSurface, PomoBreakEnd:
+		return a, nil
+	case "":
+		return PomoBreakContinue, nil
+	}
+	return "", fmt.Errorf("invalid pomodoro break action %q: expected continue|surface|end", s)
+}
+
+func newPomodoroTimer(work, brk time.Duration) timeBlockTimer {
+	return timeBlockTimer{
+		Model: timer.New(work),
+		pomo: &pomodoro{
+			work: work,
+			brk:  brk,
+		},
+	}
+}
+
+// nextInterval switches between work and break intervals
+func (t timeBlockTimer) nextInterval() timeBlockTimer {
+	p := *t.pomo
+	if p.onBreak {
+		p.onBreak = false
+		t.Model = timer.NewWithInterval(p.work, t.Interval)
+	} else {
+		p.onBreak = true
+		p.completed++
+		t.Model = timer.NewWithInterval(p.brk, t.Interval)
+	}
+	t.pomo = &p
+	return t
 }
 
 func (t timeBlockTimer) View() string {
@@ -18,5 +173,34 @@ func (t timeBlockTimer) View() string {
 	} else {
 		dur = fmt.Sprintf("%ds", int(t.Timeout.Seconds()))
 	}
-	return fmt.Sprintf("Task time blocked for %s", dur)
+
+	var view string
+	if t.pomo != nil {
+		n, phase, total := t.pomo.completed+1, "work", t.pomo.work
+		if t.pomo.onBreak {
+			n, phase, total = t.pomo.completed, "break", t.pomo.brk
+		}
+		view = fmt.Sprintf(
+			"Pomodoro %d %s %s left %s",
+			n,
+			phase,
+			dur,
+			progressBar(total-t.Timeout, total, 20),
+		)
+	} else {
+		view = fmt.Sprintf("Task time blocked for %s", dur)
+	}
+
+	if !t.Running() {
+		return view + " (paused)"
+	}
+	return view
+}
+
+func progressBar(elapsed, total time.Duration, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, max(0, int(float64(width)*float64(elapsed)/float64(total))))
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
 }
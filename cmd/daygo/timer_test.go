@@ -0,0 +1,197 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeBlockEnd(t *testing.T) {
+	now := time.Date(2025, 6, 1, 14, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		arg  string
+		want time.Time
+	}{
+		{"1530", time.Date(2025, 6, 1, 15, 30, 0, 0, time.Local)},
+		// 24h times that have passed roll over to tomorrow
+		{"0930", time.Date(2025, 6, 2, 9, 30, 0, 0, time.Local)},
+		{"1400", time.Date(2025, 6, 2, 14, 0, 0, 0, time.Local)},
+		{"25m", now.Add(25 * time.Minute)},
+		{"1h30", now.Add(90 * time.Minute)},
+		{"1h30m", now.Add(90 * time.Minute)},
+	} {
+		// act
+		got, err := parseTimeBlockEnd(tc.arg, now)
+
+		// assert
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.arg, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("%s: want %v, got %v", tc.arg, tc.want, got)
+		}
+	}
+
+	for _, arg := range []string{"2400", "930", "-5m", "0m", "soon"} {
+		if _, err := parseTimeBlockEnd(arg, now); err == nil {
+			t.Fatalf("%s: want error, got nil", arg)
+		}
+	}
+}
+
+func TestTimeBlockWarnings(t *testing.T) {
+	// arrange
+	warnings, err := ParseTimeBlockWarnings("5m, 10m,1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb := newTimeBlockTimer(8*time.Minute, warnings)
+
+	// assert
+	if want := []time.Duration{5 * time.Minute, time.Minute}; !slices.Equal(tb.warnings, want) {
+		t.Fatalf("want %v, got %v", want, tb.warnings)
+	}
+	if _, ok := tb.popWarning(); ok {
+		t.Fatalf("want no warning with %s left", tb.Timeout)
+	}
+
+	// act
+	tb.Timeout = 4 * time.Minute
+	w, ok := tb.popWarning()
+
+	// assert
+	if !ok || w != 5*time.Minute {
+		t.Fatalf("want 5m warning, got %v %v", w, ok)
+	}
+	if _, ok := tb.popWarning(); ok {
+		t.Fatal("want 5m warning to fire once")
+	}
+
+	// act
+	tb.Timeout = 30 * time.Second
+	w, ok = tb.popWarning()
+
+	// assert
+	if !ok || w != time.Minute {
+		t.Fatalf("want 1m warning, got %v %v", w, ok)
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	work := newPomodoroTimer(25*time.Minute, 5*time.Minute)
+	for _, tc := range []struct {
+		name          string
+		from          timeBlockTimer
+		wantOnBreak   bool
+		wantCompleted int
+		wantTimeout   time.Duration
+	}{
+		{"work to break", work, true, 1, 5 * time.Minute},
+		{"break to work", work.nextInterval(), false, 1, 25 * time.Minute},
+		{"second break", work.nextInterval().nextInterval(), true, 2, 5 * time.Minute},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := *tc.from.pomo
+
+			// act
+			got := tc.from.nextInterval()
+
+			// assert
+			if got.pomo.onBreak != tc.wantOnBreak || got.pomo.completed != tc.wantCompleted || got.Timeout != tc.wantTimeout {
+				t.Fatalf("want on break %v, %d completed, %v timeout, got %+v, %v timeout", tc.wantOnBreak, tc.wantCompleted, tc.wantTimeout, *got.pomo, got.Timeout)
+			}
+			if *tc.from.pomo != before {
+				t.Fatalf("want %+v left unchanged, got %+v", before, *tc.from.pomo)
+			}
+		})
+	}
+}
+
+func TestAdvancePomodoro(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		action       PomoBreakAction
+		onBreak      bool
+		ended        bool
+		queued       bool
+		wantPending  bool
+		wantPaused   bool
+		wantCurrent  string
+		wantUpNext   bool
+		wantPomodoro bool
+		wantSaved    bool
+	}{
+		{name: "continue pauses for break", action: PomoBreakContinue, queued: true, wantPending: true, wantPaused: true, wantCurrent: "write report", wantPomodoro: true, wantSaved: true},
+		{name: "surface shows next task", action: PomoBreakSurface, queued: true, wantPending: true, wantPaused: true, wantCurrent: "write report", wantUpNext: true, wantPomodoro: true, wantSaved: true},
+		{name: "end ends task for break", action: PomoBreakEnd, queued: true, wantCurrent: "write report", wantUpNext: true, wantPomodoro: true},
+		{name: "break over resumes task", action: PomoBreakContinue, onBreak: true, wantPending: true, wantCurrent: "write report", wantPomodoro: true, wantSaved: true},
+		{name: "break over starts next task", action: PomoBreakEnd, onBreak: true, ended: true, queued: true, wantPending: true, wantCurrent: "review PR", wantPomodoro: true},
+		{name: "break over with empty queue", action: PomoBreakEnd, onBreak: true, ended: true, wantCurrent: "write report"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			svc := &fakeTaskSvc{}
+			m, _, _ := newUndoModel(svc)
+			m.h = 40
+			m.opts.pomoBreakAction = tc.action
+			m.tbTimer = newPomodoroTimer(25*time.Minute, 5*time.Minute)
+			m.tbTimer.Interval = time.Millisecond
+			if tc.onBreak {
+				m.tbTimer = m.tbTimer.nextInterval()
+				m.currentTask().TogglePause(time.Now().Add(-5 * time.Minute))
+			}
+			if tc.ended {
+				if _, err := m.endPendingTask(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !tc.queued {
+				m.taskQueue = NewTaskQueue(nil)
+			}
+
+			// act
+			m, cmd := m.advancePomodoro()
+			runCmds(cmd)
+
+			// assert
+			got := m.currentTask()
+			if got.Name != tc.wantCurrent || got.IsPending() != tc.wantPending || got.IsPaused() != tc.wantPaused {
+				t.Fatalf("want %q pending %v paused %v, got %+v", tc.wantCurrent, tc.wantPending, tc.wantPaused, got)
+			}
+			upNext := slices.ContainsFunc(m.alerts, func(a string) bool { return strings.Contains(a, "Up next: review PR") })
+			if upNext != tc.wantUpNext {
+				t.Fatalf("want up next alert %v, got %v", tc.wantUpNext, m.alerts)
+			}
+			if (m.tbTimer.pomo != nil) != tc.wantPomodoro {
+				t.Fatalf("want pomodoro running %v, got %+v", tc.wantPomodoro, m.tbTimer.pomo)
+			}
+			if saved := len(svc.pauses) > 0; saved != tc.wantSaved || (saved && svc.pauses[len(svc.pauses)-1].EndedAt.IsZero() != tc.wantPaused) {
+				t.Fatalf("want pauses saved %v with paused %v, got %+v", tc.wantSaved, tc.wantPaused, svc.pauses)
+			}
+			if !tc.onBreak && (m.taskLog[0].Pomodoros != 1 || !m.tbTimer.pomo.onBreak) {
+				t.Fatalf("want 1 pomodoro done and on break, got %d and %+v", m.taskLog[0].Pomodoros, m.tbTimer.pomo)
+			}
+		})
+	}
+}
+
+func TestPomoOff_SavesResumedPause(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m.tbTimer = newPomodoroTimer(25*time.Minute, 5*time.Minute).nextInterval()
+	m.currentTask().TogglePause(time.Now().Add(-time.Minute))
+
+	// act
+	m, cmd := m.handleInput("/pomo off")
+	runCmds(cmd)
+
+	// assert
+	if m.currentTask().IsPaused() || m.tbTimer.pomo != nil {
+		t.Fatalf("want pomodoro stopped and task resumed, got %+v", m.currentTask().Pauses)
+	}
+	if len(svc.pauses) != 1 || svc.pauses[0].EndedAt.IsZero() {
+		t.Fatalf("want resumed pause saved, got %+v", svc.pauses)
+	}
+}
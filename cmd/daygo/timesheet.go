@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const timesheetUsage = "usage: daygo /timesheet [--format toggl|clockify] [--week|--month|--from YYYY-MM-DD [--to YYYY-MM-DD]] [--round <duration>] [--email <email>]"
+
+// TimesheetConfig is how tracked time is billed
+type TimesheetConfig struct {
+	// Rounding is the increment durations are rounded up to; 0 disables rounding
+	Rounding time.Duration
+	// Projects maps tags to project names
+	Projects map[string]string
+}
+
+// ParseTimesheetProjects parses a comma separated list of tag=project
+func ParseTimesheetProjects(s string) (map[string]string, error) {
+	projects := make(map[string]string)
+	for f := range strings.SplitSeq(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		tag, project, ok := strings.Cut(f, "=")
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		project = strings.TrimSpace(project)
+		if !ok || tag == "" || project == "" {
+			return nil, fmt.Errorf("invalid timesheet project %q", f)
+		}
+		projects[tag] = project
+	}
+	return projects, nil
+}
+
+// timesheetEntry is a billable entry of one or more consecutive sessions of a task
+type timesheetEntry struct {
+	name  string
+	tags  []string
+	start time.Time
+	// duration excludes pauses and the time between sessions
+	duration time.Duration
+}
+
+// timesheetEntries merges ended tasks into entries. A task is merged into the
+// previous entry if it was started the same day and has the same name or
+// continues the entry's last session.
+func timesheetEntries(tasks []Task) []timesheetEntry {
+	var entries []timesheetEntry
+	var lastID uuid.UUID
+	for _, t := range tasks {
+		if t.EndedAt.IsZero() {
+			continue
+		}
+		if n := len(entries); n > 0 {
+			prev := &entries[n-1]
+			sameDay := startOfDay(prev.start).Equal(startOfDay(t.StartedAt))
+			if sameDay && (t.Name == prev.name || (t.ContinuesID != uuid.Nil && t.ContinuesID == lastID)) {
+				prev.duration += t.Duration()
+				lastID = t.ID
+				continue
+			}
+		}
+		entries = append(entries, timesheetEntry{
+			name:     t.Name,
+			tags:     t.Tags,
+			start:    t.StartedAt,
+			duration: t.Duration(),
+		})
+		lastID = t.ID
+	}
+	return entries
+}
+
+// roundUp rounds d up to the increment
+func roundUp(d, increment time.Duration) time.Duration {
+	if increment <= 0 {
+		return d
+	}
+	if r := d % increment; r > 0 {
+		d += increment - r
+	}
+	return d
+}
+
+// formatHMS formats d as hh:mm:ss as expected by time trackers
+func formatHMS(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// runTimesheet writes a CSV timesheet of the tasks ended within the
+// timesheet's days that can be imported into Toggl or Clockify
+func runTimesheet(ctx context.Context, taskSvc TaskSvc, args []string, w io.Writer, conf TimesheetConfig) error {
+	fs := flag.NewFlagSet("timesheet", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "toggl", "")
+	days := newRangeFlags(fs)
+	round := fs.Duration("round", conf.Rounding, "")
+	email := fs.String("email", "", "")
+	if positional, err := parseArgs(fs, args); err != nil || len(positional) > 0 {
+		return fmt.Errorf("%s", timesheetUsage)
+	}
+	if *format != "toggl" && *format != "clockify" {
+		return fmt.Errorf("unknown format %q: %s", *format, timesheetUsage)
+	}
+	from, to, err := days.days(time.Now())
+	if err != nil {
+		return err
+	}
+
+	tasks, err := taskSvc.GetTasksByStartTime(ctx, from, to.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"Email", "Project", "Description", "Start date", "Start time", "Duration", "Tags"}
+	if *format == "clockify" {
+		header = []string{"Project", "Description", "Email", "Tags", "Start Date", "Start Time", "End Date", "End Time", "Duration (h)"}
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range timesheetEntries(tasks) {
+		d := roundUp(e.duration, *round)
+		if d <= 0 {
+			continue
+		}
+		var project string
+		for _, tag := range e.tags {
+			if p, ok := conf.Projects[tag]; ok {
+				project = p
+				break
+			}
+		}
+		description := withoutTags(e.name)
+		tags := strings.Join(e.tags, ", ")
+		end := e.start.Add(d)
+
+		row := []string{*email, project, description, e.start.Format(dateFormat), e.start.Format(time.TimeOnly), formatHMS(d), tags}
+		if *format == "clockify" {
+			row = []string{project, description, *email, tags, e.start.Format(dateFormat), e.start.Format(time.TimeOnly), end.Format(dateFormat), end.Format(time.TimeOnly), formatHMS(d)}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
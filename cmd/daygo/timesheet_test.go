@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTimesheetEntries_MergesConsecutiveSessions(t *testing.T) {
+	// arrange
+	tasks := newExportedTasks()
+	session := func(name string, start time.Time, d time.Duration) Task {
+		t := TaskFromName(name)
+		t.ID = uuid.New()
+		t.StartedAt = start
+		t.EndedAt = start.Add(d)
+		return t
+	}
+	day := tasks[1].StartedAt
+	again := session(tasks[1].Name, tasks[1].EndedAt.Add(10*time.Minute), 20*time.Minute)
+	followUp := session("polish report #work", again.EndedAt, 15*time.Minute)
+	followUp.ContinuesID = again.ID
+	other := session("review PR", followUp.EndedAt, 5*time.Minute)
+	nextDay := session(other.Name, day.AddDate(0, 0, 1), 5*time.Minute)
+	tasks = append(tasks, again, followUp, other, nextDay)
+
+	// act
+	entries := timesheetEntries(tasks)
+
+	// assert
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	if want := []string{"write report #work", "finish report #work", "review PR", "review PR"}; !slices.Equal(names, want) {
+		t.Fatalf("want %v, got %v", want, names)
+	}
+	if want := 65 * time.Minute; entries[1].duration != want {
+		t.Fatalf("want %v, got %v", want, entries[1].duration)
+	}
+	if !entries[1].start.Equal(day) {
+		t.Fatalf("want %v, got %v", day, entries[1].start)
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct {
+		d, increment, want time.Duration
+	}{
+		{50 * time.Minute, 15 * time.Minute, time.Hour},
+		{time.Hour, 15 * time.Minute, time.Hour},
+		{time.Minute, 6 * time.Minute, 6 * time.Minute},
+		{50*time.Minute + 10*time.Second, 0, 50*time.Minute + 10*time.Second},
+	}
+	for _, tt := range tests {
+		if got := roundUp(tt.d, tt.increment); got != tt.want {
+			t.Fatalf("want %v, got %v", tt.want, got)
+		}
+	}
+}
+
+func TestParseTimesheetProjects(t *testing.T) {
+	// act
+	projects, err := ParseTimesheetProjects("work=Acme Corp, #oss = Open Source")
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	if projects["work"] != "Acme Corp" || projects["oss"] != "Open Source" {
+		t.Fatalf("want work and oss projects, got %v", projects)
+	}
+	if _, err := ParseTimesheetProjects("work"); err == nil {
+		t.Fatalf("want error, got nil")
+	}
+}
+
+func TestRunTimesheet(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"toggl", []string{"me@example.com", "Acme Corp", "write report", "2025-03-03", "09:00:00", "01:00:00", "work"}},
+		{"clockify", []string{"Acme Corp", "write report", "me@example.com", "work", "2025-03-03", "09:00:00", "2025-03-03", "10:00:00", "01:00:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			// arrange
+			svc := &fakeTaskSvc{ended: newExportedTasks()}
+			conf := TimesheetConfig{Rounding: 15 * time.Minute, Projects: map[string]string{"work": "Acme Corp"}}
+			var buf bytes.Buffer
+
+			// act
+			err := runTimesheet(context.Background(), svc, []string{"--format", tt.format, "--email", "me@example.com"}, &buf, conf)
+
+			// assert
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			rows, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 3 {
+				t.Fatalf("want header and 2 rows, got %v", rows)
+			}
+			if !slices.Equal(rows[1], tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, rows[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+const (
+	undoUsage = "usage: /u [n]"
+	// maxUndoActions is how many actions are kept to undo
+	maxUndoActions = 50
+)
+
+type undoKind int
+
+const (
+	undoDelete     undoKind = iota // the task was deleted
+	undoDeleteNote                 // the task's last note was deleted
+	undoSkip                       // the task was requeued
+	undoEnd                        // the task was ended
+	undoEdit                       // the task or one of its notes was renamed or moved
+)
+
+// undoAction is an action taken on the task log that /u can reverse
+type undoAction struct {
+	kind undoKind
+	// task is the affected task as it was before the action
+	task Task
+	// started is the task started in place of the affected task, if any
+	started Task
+	// at is when the action was taken
+	at time.Time
+}
+
+func (a undoAction) String() string {
+	switch a.kind {
+	case undoDelete:
+		return fmt.Sprintf("delete of \"%s\"", a.task.Name)
+	case undoDeleteNote:
+		return fmt.Sprintf("delete of note \"%s\"", a.task.LastNote().Name)
+	case undoSkip:
+		return fmt.Sprintf("skip of \"%s\"", a.task.Name)
+	case undoEnd:
+		return fmt.Sprintf("end of \"%s\"", a.task.Name)
+	default:
+		return fmt.Sprintf("edit of \"%s\"", a.task.Name)
+	}
+}
+
+// cloneTask copies the task's notes, subtasks and pauses so that the copy
+// isn't changed along with the task
+func cloneTask(t Task) Task {
+	t.Notes = slices.Clone(t.Notes)
+	t.Subtasks = slices.Clone(t.Subtasks)
+	t.Pauses = slices.Clone(t.Pauses)
+	return t
+}
+
+// sameTask compares tasks by ID, or by start time if they weren't persisted
+func sameTask(a, b Task) bool {
+	if a.ID != uuid.Nil || b.ID != uuid.Nil {
+		return a.ID == b.ID
+	}
+	return a.StartedAt.Equal(b.StartedAt)
+}
+
+// recordUndo logs an action taken on the task, given as it was before the
+// action. The current task is recorded as started in its place if it differs.
+func (m *model) recordUndo(kind undoKind, before Task, at time.Time) {
+	a := undoAction{
+		kind: kind,
+		task: before,
+		at:   at,
+	}
+	if curr := m.currentTask(); curr.IsPending() && !sameTask(*curr, before) {
+		a.started = *curr
+	}
+	m.undoLog = append(m.undoLog, a)
+	if len(m.undoLog) > maxUndoActions {
+		m.undoLog = slices.Delete(m.undoLog, 0, len(m.undoLog)-maxUndoActions)
+	}
+}
+
+// undo reverses the last n actions in the task log and persists their
+// inverse, stopping at the first action that can no longer be undone
+func (m *model) undo(n int) tea.Cmd {
+	if len(m.undoLog) == 0 {
+		m.addAlert(colorRed, "nothing to undo")
+		return nil
+	}
+
+	var steps []UndoStep
+	var cmds []tea.Cmd
+	for range n {
+		if len(m.undoLog) == 0 {
+			break
+		}
+		a := m.undoLog[len(m.undoLog)-1]
+		m.undoLog = m.undoLog[:len(m.undoLog)-1]
+		step, cmd, err := m.revert(a)
+		if err != nil {
+			m.addAlert(colorRed, "%s", err)
+			break
+		}
+		steps = append(steps, step)
+		cmds = append(cmds, cmd)
+		m.addAlert(colorCyan, "Undid %s", a)
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	return tea.Batch(append(cmds, func() tea.Msg {
+		timeout, c := m.newTimeout()
+		defer c()
+		if err := m.taskSvc.Undo(timeout, steps); err != nil {
+			return ErrorMsg{
+				err: err,
+			}
+		}
+		return nil
+	})...)
+}
+
+// revert reverses the action in the task log, returning how to persist it.
+// Returns error if the task log has changed such that it can't be reversed.
+func (m *model) revert(a undoAction) (UndoStep, tea.Cmd, error) {
+	var step UndoStep
+	curr := m.currentTask()
+
+	switch a.kind {
+	case undoEdit:
+		i := slices.IndexFunc(m.taskLog, func(t Task) bool {
+			return sameTask(t, a.task)
+		})
+		if i < 0 {
+			return step, nil, fmt.Errorf("can't undo %s: no longer in the task log", a)
+		}
+		t := &m.taskLog[i]
+		edited := cloneTask(*t)
+		revertEdit(t, a.task)
+		step.Edit = append(step.Edit, TaskEdit{Before: edited, After: cloneTask(*t)})
+		return step, nil, nil
+	case undoDeleteNote:
+		if !curr.IsPending() || !sameTask(*curr, a.task) {
+			return step, nil, fmt.Errorf("can't undo %s: no longer the current task", a)
+		}
+	default:
+		if a.started.StartedAt.IsZero() {
+			if curr.IsPending() {
+				return step, nil, fmt.Errorf("can't undo %s: \"%s\" was started since", a, curr.Name)
+			}
+		} else if !curr.IsPending() || !sameTask(*curr, a.started) {
+			return step, nil, fmt.Errorf("can't undo %s: \"%s\" is no longer the current task", a, a.started.Name)
+		}
+	}
+
+	switch a.kind {
+	case undoDeleteNote:
+		note := *a.task.LastNote()
+		i := len(curr.Notes)
+		for i > 0 && curr.Notes[i-1].StartedAt.After(note.StartedAt) {
+			i--
+		}
+		if i > 0 {
+			curr.Notes[i-1].EndedAt = note.StartedAt
+		}
+		if i < len(curr.Notes) {
+			note.EndedAt = curr.Notes[i].StartedAt
+		}
+		curr.Notes = slices.Insert(curr.Notes, i, note)
+		return step, nil, nil
+	}
+
+	if !a.started.StartedAt.IsZero() {
+		m.unstart(&step)
+	}
+	t := a.task
+	if c := m.currentTask(); a.kind == undoEnd && c != nil && sameTask(*c, t) {
+		// the ended task is resumed in its place
+		m.removeCurrentTask()
+	}
+	if t.ID != uuid.Nil {
+		switch a.kind {
+		case undoDelete:
+			if !t.CreatedAt.IsZero() {
+				step.Restore = append(step.Restore, t)
+				step.Forget = append(step.Forget, daygo.TaskActionRecord{TaskID: t.ID, Name: t.Name, Action: daygo.TaskActionDiscard})
+			}
+		case undoSkip:
+			m.taskQueue.Remove(t.ID)
+			step.Save = append(step.Save, t)
+			step.Forget = append(step.Forget, daygo.TaskActionRecord{TaskID: t.ID, Name: t.Name, Action: daygo.TaskActionSkip})
+		case undoEnd:
+			ended := t
+			ended.EndedAt = a.at
+			step.Reopen = append(step.Reopen, ended)
+			// pauses are inserted again when the task ends
+			for i := range t.Pauses {
+				t.Pauses[i].ID = 0
+			}
+		}
+	}
+	if m.tbTimer.pomo == nil {
+		m.tbTimer = timeBlockTimer{}
+	}
+	return step, m.resumeTask(t), nil
+}
+
+// revertEdit restores the names and times of the task and its notes as they
+// were before an edit. Ends set since the edit are kept.
+func revertEdit(t *Task, before Task) {
+	t.Name = before.Name
+	t.Tags = before.Tags
+	t.StartedAt = before.StartedAt
+	if !before.EndedAt.IsZero() {
+		t.EndedAt = before.EndedAt
+	}
+	for i, n := range before.Notes {
+		if i >= len(t.Notes) {
+			break
+		}
+		t.Notes[i].Name = n.Name
+		t.Notes[i].StartedAt = n.StartedAt
+		if !n.EndedAt.IsZero() {
+			t.Notes[i].EndedAt = n.EndedAt
+		}
+	}
+}
+
+// unstart removes the current task from the task log, returning it to the
+// queue if it was dequeued or deleting it otherwise
+func (m *model) unstart(step *UndoStep) {
+	t := m.removeCurrentTask()
+	if t.QueuedAt.IsZero() {
+		if t.ID != uuid.Nil {
+			step.Delete = append(step.Delete, t.ID)
+		}
+		return
+	}
+	t.StartedAt = time.Time{}
+	t.TimeBlockAt = time.Time{}
+	m.taskQueue.Sync([]Task{t})
+	step.Save = append(step.Save, t)
+}
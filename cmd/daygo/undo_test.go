@@ -0,0 +1,178 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runCmds runs the command and any commands it batches, discarding their messages
+func runCmds(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runCmds(c)...)
+	}
+	return msgs
+}
+
+// applyCmds runs cmd and updates the model with the results of its writes
+func applyCmds(m model, cmd tea.Cmd) model {
+	for _, msg := range runCmds(cmd) {
+		switch msg.(type) {
+		case TaskStartedMsg, TaskEditedMsg, PausesSavedMsg:
+			updated, next := m.Update(msg)
+			m = applyCmds(updated.(model), next)
+		}
+	}
+	return m
+}
+
+// newUndoModel returns a model working on "write report" with "review PR" queued
+func newUndoModel(svc *fakeTaskSvc) (model, Task, Task) {
+	curr := newPersistedTask("write report")
+	curr.StartedAt = time.Now().Add(-time.Hour)
+	curr.CreatedAt = curr.StartedAt
+	next := newPersistedTask("review PR")
+	m := NewModel(svc, []Task{curr}, nil, modelOptions{})
+	m.taskQueue = NewTaskQueue([]Task{next})
+	return m, curr, next
+}
+
+func TestUndo_Skip(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, next := newUndoModel(svc)
+	m, cmd := m.handleInput("/k")
+	runCmds(cmd)
+	m.taskQueue.Queue(svc.pending[0])
+
+	// act
+	m, cmd = m.handleInput("/u")
+	runCmds(cmd)
+
+	// assert
+	if got := m.currentTask(); got.ID != curr.ID || !got.StartedAt.Equal(curr.StartedAt) {
+		t.Fatalf("want %q resumed, got %+v", curr.Name, got)
+	}
+	if m.taskQueue.Size() != 1 || m.taskQueue.Peek().ID != next.ID || !m.taskQueue.Peek().StartedAt.IsZero() {
+		t.Fatalf("want %q requeued, got %+v", next.Name, m.taskQueue.Peek())
+	}
+	if len(svc.undone) != 1 || len(svc.undone[0].Save) != 2 || len(svc.undone[0].Forget) != 1 {
+		t.Fatalf("want requeued task and skipped task saved, got %+v", svc.undone)
+	}
+	if f := svc.undone[0].Forget[0]; f.TaskID != curr.ID || f.Action != daygo.TaskActionSkip {
+		t.Fatalf("want skip of %v forgotten, got %+v", curr.ID, f)
+	}
+}
+
+func TestUndo_End(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+	m.addNote("outlined")
+	m, cmd := m.handleInput("/n draft slides")
+	m = applyCmds(m, cmd)
+	started := m.currentTask().ID
+
+	// act
+	m, cmd = m.handleInput("/u")
+	runCmds(cmd)
+
+	// assert
+	if len(m.taskLog) != 1 {
+		t.Fatalf("want 1 task in log, got %d", len(m.taskLog))
+	}
+	got := m.currentTask()
+	if got.ID != curr.ID || !got.IsPending() || got.LastNote().Name != "outlined" || !got.LastNote().EndedAt.IsZero() {
+		t.Fatalf("want %q reopened with its note, got %+v", curr.Name, got)
+	}
+	if len(svc.undone) != 1 || len(svc.undone[0].Delete) != 1 || svc.undone[0].Delete[0] != started {
+		t.Fatalf("want started task deleted, got %+v", svc.undone)
+	}
+	if r := svc.undone[0].Reopen; len(r) != 1 || r[0].ID != curr.ID || r[0].EndedAt.IsZero() {
+		t.Fatalf("want %q reopened, got %+v", curr.Name, r)
+	}
+}
+
+func TestUndo_Delete(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+	m.taskQueue = NewTaskQueue(nil)
+	m.addNote("outlined")
+	m, _ = m.handleInput("/x")
+	m, _ = m.handleInput("/x")
+
+	// act
+	m, cmd := m.handleInput("/u 2")
+	runCmds(cmd)
+
+	// assert
+	got := m.currentTask()
+	if got.ID != curr.ID || !got.IsPending() || len(got.Notes) != 1 || got.Notes[0].Name != "outlined" {
+		t.Fatalf("want %q restored with its note, got %+v", curr.Name, got)
+	}
+	if len(svc.undone) != 2 || len(svc.undone[0].Restore) != 1 || svc.undone[0].Restore[0].ID != curr.ID {
+		t.Fatalf("want %q restored, got %+v", curr.Name, svc.undone)
+	}
+	if f := svc.undone[0].Forget; len(f) != 1 || f[0].Action != daygo.TaskActionDiscard {
+		t.Fatalf("want discard forgotten, got %+v", f)
+	}
+}
+
+func TestUndo_Edit(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, curr, _ := newUndoModel(svc)
+	m, cmd := m.handleInput("/e write report #work")
+	m = applyCmds(m, cmd)
+	if got := m.currentTask().Name; got != "write report #work" {
+		t.Fatalf("want %q, got %q", "write report #work", got)
+	}
+
+	// act
+	m, cmd = m.handleInput("/u")
+	runCmds(cmd)
+
+	// assert
+	if got := m.currentTask(); got.Name != curr.Name || len(got.Tags) != 0 {
+		t.Fatalf("want %q, got %q", curr.Name, got.Name)
+	}
+	if len(svc.undone) != 1 || len(svc.undone[0].Edit) != 1 || svc.undone[0].Edit[0].After.Name != curr.Name {
+		t.Fatalf("want %q persisted, got %+v", curr.Name, svc.undone)
+	}
+}
+
+func TestUndo_RefusesWhenTaskLogChanged(t *testing.T) {
+	// arrange
+	svc := &fakeTaskSvc{}
+	m, _, _ := newUndoModel(svc)
+	m.taskQueue = NewTaskQueue(nil)
+	m, _ = m.handleInput("/x")
+	m, _ = m.handleInput("draft slides")
+
+	// act
+	m, cmd := m.handleInput("/u")
+	runCmds(cmd)
+
+	// assert
+	if got := m.currentTask(); got.Name != "draft slides" {
+		t.Fatalf("want %q, got %q", "draft slides", got.Name)
+	}
+	if len(svc.undone) != 0 {
+		t.Fatalf("want nothing undone, got %+v", svc.undone)
+	}
+	if len(m.undoLog) != 0 {
+		t.Fatalf("want undo log emptied, got %d actions", len(m.undoLog))
+	}
+}
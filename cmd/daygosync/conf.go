@@ -13,6 +13,9 @@ const (
 	KeyPort        config.Key = "DAYGO_SYNC_PORT"
 	KeyLogLevel    config.Key = "DAYGO_SYNC_LOG_LEVEL"
 	KeyLogPath     config.Key = "DAYGO_SYNC_LOG_PATH"
+
+	KeyWebhookAttempts config.Key = "DAYGO_SYNC_WEBHOOK_ATTEMPTS"
+	KeyWebhookBackoff  config.Key = "DAYGO_SYNC_WEBHOOK_BACKOFF"
 )
 
 var userHomeDir, _ = os.UserHomeDir()
@@ -35,6 +38,14 @@ func LoadConf(src string) (config.Config, error) {
 			Key:     KeyLogPath,
 			Default: path.Join(userHomeDir, ".daygo", "sync.log"),
 		},
+		{
+			Key:     KeyWebhookAttempts,
+			Default: "3",
+		},
+		{
+			Key:     KeyWebhookBackoff,
+			Default: "1s",
+		},
 	}
 
 	return env.NewConfig(src, entries...)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Thiht/transactor"
@@ -16,12 +17,20 @@ import (
 
 type SyncController interface {
 	Sync(http.ResponseWriter, *http.Request)
+	ClaimRecurrence(http.ResponseWriter, *http.Request)
+	RegisterWebhook(http.ResponseWriter, *http.Request)
+	GetWebhooks(http.ResponseWriter, *http.Request)
+	DeleteWebhook(http.ResponseWriter, *http.Request)
+	GetWebhookDeadLetters(http.ResponseWriter, *http.Request)
 }
 
 type controller struct {
-	transactor transactor.Transactor
-	taskRepo   daygo.TaskRepo
-	logger     daygo.Logger
+	transactor     transactor.Transactor
+	taskRepo       daygo.TaskRepo
+	recurrenceRepo daygo.RecurrenceRepo
+	webhookRepo    daygo.WebhookRepo
+	webhooks       *webhookDispatcher
+	logger         daygo.Logger
 }
 
 type httpError struct {
@@ -48,15 +57,20 @@ func (c *controller) Sync(w http.ResponseWriter, r *http.Request) {
 	c.logger.Info("Sync", "request", syncReq)
 
 	// Process client tasks with conflict resolution within transaction
-	toServerSyncCount := 0
+	var events []daygo.WebhookEvent
 	err := c.transactor.WithinTransaction(r.Context(), func(ctx context.Context) error {
-		cnt, err := c.syncClientTasks(ctx, syncReq.ClientTasks)
-		toServerSyncCount = cnt
-		return err
+		synced, err := c.syncClientTasks(ctx, syncReq.ClientTasks)
+		if err != nil {
+			return err
+		}
+		events = synced
+		return c.syncClientRecurrences(ctx, syncReq.ClientRecurrences)
 	})
 	if c.logAndWriteError(w, err) {
 		return
 	}
+	toServerSyncCount := len(events)
+	c.webhooks.Dispatch(events)
 
 	// Return server tasks to client
 	serverTasks, err := c.taskRepo.GetByCreateTime(r.Context(), syncReq.LastSyncTime, time.Time{})
@@ -67,8 +81,17 @@ func (c *controller) Sync(w http.ResponseWriter, r *http.Request) {
 		c.logAndWriteError(w, httpErr)
 		return
 	}
+	serverRecurrences, err := c.recurrenceRepo.GetRecurrencesByUpdateTime(r.Context(), syncReq.LastSyncTime, time.Time{})
+	if err != nil {
+		httpErr := httpError{
+			msg: "failed to get server recurrences: " + err.Error(),
+		}
+		c.logAndWriteError(w, httpErr)
+		return
+	}
 	response := daygo.SyncResponse{
 		ServerTasks:       serverTasks,
+		ServerRecurrences: serverRecurrences,
 		ToServerSyncCount: toServerSyncCount,
 	}
 	c.logger.Info("Sync", "response", response)
@@ -80,6 +103,150 @@ func (c *controller) Sync(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ClaimRecurrence advances a recurrence past the requested occurrence if no
+// other client has claimed it yet
+func (c *controller) ClaimRecurrence(w http.ResponseWriter, r *http.Request) {
+	var claimReq daygo.ClaimRecurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&claimReq); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.logger.Info("ClaimRecurrence", "request", claimReq)
+
+	var response daygo.ClaimRecurrenceResponse
+	err := c.transactor.WithinTransaction(r.Context(), func(ctx context.Context) error {
+		existing, err := c.recurrenceRepo.GetRecurrence(ctx, claimReq.RecurrenceID)
+		if err != nil {
+			if errors.Is(err, sqlite.ErrNotFound) {
+				return httpError{
+					code: http.StatusNotFound,
+					msg:  "Recurrence not found",
+				}
+			}
+			return err
+		}
+		response.Recurrence = existing
+		if existing.NextAt.After(claimReq.OccurrenceAt) || !existing.DeletedAt.IsZero() {
+			// already claimed or deleted
+			return nil
+		}
+
+		spec, err := daygo.ParseRecurrenceSpec(existing.Spec)
+		if err != nil {
+			return httpError{
+				code: http.StatusInternalServerError,
+				msg:  "Invalid recurrence spec: " + err.Error(),
+			}
+		}
+		existing.NextAt = spec.Advance(claimReq.OccurrenceAt, time.Now())
+		updated, err := c.recurrenceRepo.UpdateRecurrence(ctx, existing.ID, existing.RecurrenceRecord)
+		if err != nil {
+			return err
+		}
+		response.Recurrence = updated
+		response.Claimed = true
+		return nil
+	})
+	if c.logAndWriteError(w, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *controller) RegisterWebhook(w http.ResponseWriter, r *http.Request) {
+	var req daygo.RegisterWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" {
+		http.Error(w, "Provide url", http.StatusBadRequest)
+		return
+	}
+	c.logger.Info("RegisterWebhook", "url", req.URL, "tags", req.Tags)
+
+	inserted, err := c.webhookRepo.InsertWebhook(r.Context(), daygo.WebhookRecord{
+		URL:    req.URL,
+		Tags:   req.Tags,
+		Secret: req.Secret,
+	})
+	if c.logAndWriteError(w, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toWebhookResponse(inserted)); err != nil {
+		c.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+func (c *controller) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := c.webhookRepo.GetWebhooks(r.Context())
+	if c.logAndWriteError(w, err) {
+		return
+	}
+
+	response := make([]daygo.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, toWebhookResponse(webhook))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *controller) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+	c.logger.Info("DeleteWebhook", "id", id)
+
+	if _, err := c.webhookRepo.DeleteWebhook(r.Context(), id); err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			err = httpError{
+				code: http.StatusNotFound,
+				msg:  "Webhook not found",
+			}
+		}
+		c.logAndWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *controller) GetWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deadLetters, err := c.webhookRepo.GetDeadLetters(r.Context())
+	if c.logAndWriteError(w, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deadLetters); err != nil {
+		http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func toWebhookResponse(webhook daygo.ExistingWebhookRecord) daygo.WebhookResponse {
+	return daygo.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Tags:      webhook.Tags,
+		Signed:    webhook.Secret != "",
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
 func (c *controller) logAndWriteError(w http.ResponseWriter, err error) bool {
 	if err == nil {
 		return false
@@ -94,7 +261,8 @@ func (c *controller) logAndWriteError(w http.ResponseWriter, err error) bool {
 	return true
 }
 
-func (c *controller) syncClientTasks(ctx context.Context, tasks []daygo.ExistingTaskRecord) (int, error) {
+// syncClientTasks returns an event for each task inserted or updated
+func (c *controller) syncClientTasks(ctx context.Context, tasks []daygo.ExistingTaskRecord) ([]daygo.WebhookEvent, error) {
 	var existingTaskIDs []any
 	for _, clientTask := range tasks {
 		if clientTask.ID != uuid.Nil {
@@ -106,7 +274,7 @@ func (c *controller) syncClientTasks(ctx context.Context, tasks []daygo.Existing
 	if len(existingTaskIDs) > 0 {
 		existing, err := c.taskRepo.GetTasks(ctx, existingTaskIDs)
 		if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
-			return 0, httpError{
+			return nil, httpError{
 				code: http.StatusInternalServerError,
 				msg:  "Failed getting existing tasks: " + err.Error(),
 			}
@@ -119,31 +287,61 @@ func (c *controller) syncClientTasks(ctx context.Context, tasks []daygo.Existing
 		taskIDToExistingRecord[task.ID.String()] = task
 	}
 
-	var cnt int
+	var events []daygo.WebhookEvent
 	for _, clientTask := range tasks {
 		serverTask, exists := taskIDToExistingRecord[clientTask.ID.String()]
 		if clientTask.ID == uuid.Nil || !exists {
 			// New task - create it
-			_, err := c.taskRepo.InsertTask(ctx, clientTask.TaskRecord)
+			inserted, err := c.taskRepo.InsertTask(ctx, clientTask.TaskRecord)
 			if err != nil {
-				return 0, httpError{
+				return nil, httpError{
 					code: http.StatusInternalServerError,
 					msg:  "Failed to create task: " + err.Error(),
 				}
 			}
-			cnt += 1
+			events = append(events, daygo.WebhookEvent{
+				Type: daygo.WebhookEventTaskCreated,
+				Task: inserted,
+				Time: time.Now(),
+			})
 		} else if clientTask.UpdatedAt.After(serverTask.UpdatedAt) {
 			// Client has newer version - update task
-			_, err := c.taskRepo.UpdateTask(ctx, clientTask.ID, clientTask.TaskRecord)
+			updated, err := c.taskRepo.UpdateTask(ctx, clientTask.ID, clientTask.TaskRecord)
 			if err != nil {
-				return 0, httpError{
+				return nil, httpError{
 					code: http.StatusInternalServerError,
 					msg:  "Failed to update task: " + err.Error(),
 				}
 			}
-			cnt += 1
+			events = append(events, daygo.WebhookEvent{
+				Type: daygo.WebhookEventTaskUpdated,
+				Task: updated,
+				Time: time.Now(),
+			})
 		}
 	}
 
-	return cnt, nil
+	return events, nil
+}
+
+func (c *controller) syncClientRecurrences(ctx context.Context, recurrences []daygo.ExistingRecurrenceRecord) error {
+	for _, clientRecurrence := range recurrences {
+		serverRecurrence, err := c.recurrenceRepo.GetRecurrence(ctx, clientRecurrence.ID)
+		if err != nil && !errors.Is(err, sqlite.ErrNotFound) {
+			return httpError{
+				code: http.StatusInternalServerError,
+				msg:  "Failed getting existing recurrence: " + err.Error(),
+			}
+		}
+		if err == nil && !clientRecurrence.Supersedes(serverRecurrence) {
+			continue
+		}
+		if _, err := c.recurrenceRepo.SyncRecurrence(ctx, clientRecurrence); err != nil {
+			return httpError{
+				code: http.StatusInternalServerError,
+				msg:  "Failed to sync recurrence: " + err.Error(),
+			}
+		}
+	}
+	return nil
 }
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"embed"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
+	"strconv"
+	"syscall"
+	"time"
 
 	txStdLib "github.com/Thiht/transactor/stdlib"
 	"github.com/charmbracelet/log"
@@ -14,6 +21,9 @@ import (
 	dsdb "github.com/benjamonnguyen/deadsimple/database/sqlite"
 )
 
+//go:embed migrations/*.sql
+var migrations embed.FS
+
 func main() {
 	// cfg
 	confDir, _ := os.UserConfigDir()
@@ -21,11 +31,21 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	var dbURL, port string
+	var dbURL, port, webhookAttempts, webhookBackoff string
 	if err := cfg.GetMany([]config.Key{
 		KeyDatabaseURL,
 		KeyPort,
-	}, &dbURL, &port); err != nil {
+		KeyWebhookAttempts,
+		KeyWebhookBackoff,
+	}, &dbURL, &port, &webhookAttempts, &webhookBackoff); err != nil {
+		panic(err)
+	}
+	attempts, err := strconv.Atoi(webhookAttempts)
+	if err != nil {
+		panic(err)
+	}
+	backoff, err := time.ParseDuration(webhookBackoff)
+	if err != nil {
 		panic(err)
 	}
 	logger := log.New(os.Stdout)
@@ -36,21 +56,56 @@ func main() {
 		panic(err)
 	}
 	defer conn.Close() //nolint:errcheck
+	if err := conn.RunMigrations(migrations); err != nil {
+		panic(err)
+	}
 	transactor, dbGetter := txStdLib.NewTransactor(conn.DB(), txStdLib.NestedTransactionsSavepoints)
 
 	// repos
 	taskRepo := sqlite.NewTaskRepo(dbGetter, logger)
+	recurrenceRepo := sqlite.NewRecurrenceRepo(dbGetter, logger)
+	webhookRepo := sqlite.NewWebhookRepo(dbGetter, logger)
 
 	// routes
+	webhooks := newWebhookDispatcher(webhookRepo, logger, attempts, backoff)
 	var c SyncController = &controller{
-		transactor: transactor,
-		taskRepo:   taskRepo,
-		logger:     logger,
+		transactor:     transactor,
+		taskRepo:       taskRepo,
+		recurrenceRepo: recurrenceRepo,
+		webhookRepo:    webhookRepo,
+		webhooks:       webhooks,
+		logger:         logger,
 	}
 
 	http.HandleFunc("POST /sync", c.Sync)
+	http.HandleFunc("POST /recurrences/claim", c.ClaimRecurrence)
+	http.HandleFunc("POST /webhooks", c.RegisterWebhook)
+	http.HandleFunc("GET /webhooks", c.GetWebhooks)
+	http.HandleFunc("DELETE /webhooks/{id}", c.DeleteWebhook)
+	http.HandleFunc("GET /webhooks/dead-letters", c.GetWebhookDeadLetters)
 
 	// Start the server
-	fmt.Printf("Starting sync server on port %s\n", port)
-	fmt.Println(http.ListenAndServe(":"+port, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Addr: ":" + port}
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Printf("Starting sync server on port %s\n", port)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		fmt.Println(err)
+	case <-ctx.Done():
+		fmt.Println("Shutting down sync server")
+		timeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(timeout); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println(err)
+		}
+	}
+
+	// deliveries started by handled requests write to the db
+	webhooks.Wait()
 }
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_continues_id;
+ALTER TABLE tasks DROP COLUMN remote;
+ALTER TABLE tasks DROP COLUMN time_block_at;
+ALTER TABLE tasks DROP COLUMN pomodoros;
+ALTER TABLE tasks DROP COLUMN continues_id;
+ALTER TABLE tasks DROP COLUMN kind;
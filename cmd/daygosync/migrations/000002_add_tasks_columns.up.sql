@@ -0,0 +1,8 @@
+ALTER TABLE tasks ADD COLUMN kind INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN continues_id TEXT REFERENCES tasks(id);
+ALTER TABLE tasks ADD COLUMN pomodoros INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN time_block_at INTEGER;
+-- only meaningful to clients but written by the shared task repo
+ALTER TABLE tasks ADD COLUMN remote INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_continues_id ON tasks(continues_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_recurrences_updated_at;
+DROP TABLE IF EXISTS recurrences;
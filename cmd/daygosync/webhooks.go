@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+// webhookDispatcher delivers task events to registered webhooks in the
+// background, retrying failed deliveries before dead-lettering them
+type webhookDispatcher struct {
+	repo        daygo.WebhookRepo
+	client      *http.Client
+	logger      daygo.Logger
+	maxAttempts int
+	// backoff is the wait before the first retry, doubling after each attempt
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+func newWebhookDispatcher(repo daygo.WebhookRepo, logger daygo.Logger, maxAttempts int, backoff time.Duration) *webhookDispatcher {
+	return &webhookDispatcher{
+		repo:        repo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		maxAttempts: max(1, maxAttempts),
+		backoff:     backoff,
+	}
+}
+
+// Dispatch sends each event to the webhooks whose tags match the event's task
+func (d *webhookDispatcher) Dispatch(events []daygo.WebhookEvent) {
+	if len(events) == 0 {
+		return
+	}
+	webhooks, err := d.repo.GetWebhooks(context.Background())
+	if err != nil {
+		d.logger.Error("failed to get webhooks", "error", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		for _, e := range events {
+			if !matchesTags(webhook.Tags, e.Task.Name) {
+				continue
+			}
+			payload, err := json.Marshal(e)
+			if err != nil {
+				d.logger.Error("failed to marshal webhook event", "error", err)
+				continue
+			}
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				d.deliver(webhook, e.Type, payload)
+			}()
+		}
+	}
+}
+
+// Wait blocks until in-flight deliveries complete
+func (d *webhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *webhookDispatcher) deliver(webhook daygo.ExistingWebhookRecord, event daygo.WebhookEventType, payload []byte) {
+	var err error
+	backoff := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if err = d.send(webhook, event, payload); err == nil {
+			return
+		}
+		d.logger.Warn("failed webhook delivery", "url", webhook.URL, "attempt", attempt, "error", err)
+		if attempt < d.maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	deadLetter := daygo.WebhookDeadLetterRecord{
+		WebhookID: webhook.ID,
+		Event:     event,
+		Payload:   string(payload),
+		Error:     err.Error(),
+		Attempts:  d.maxAttempts,
+	}
+	if _, err := d.repo.InsertDeadLetter(context.Background(), deadLetter); err != nil {
+		d.logger.Error("failed to dead-letter webhook event", "url", webhook.URL, "error", err)
+	}
+}
+
+func (d *webhookDispatcher) send(webhook daygo.ExistingWebhookRecord, event daygo.WebhookEventType, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(daygo.WebhookEventHeader, string(event))
+	if webhook.Secret != "" {
+		req.Header.Set(daygo.WebhookSignatureHeader, daygo.SignWebhookPayload(webhook.Secret, payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// matchesTags returns true if tags is empty or the task name has any of the tags
+func matchesTags(tags []string, taskName string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for w := range strings.SplitSeq(taskName, " ") {
+		if tag, ok := strings.CutPrefix(w, "#"); ok && slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+type fakeWebhookRepo struct {
+	mu          sync.Mutex
+	webhooks    []daygo.ExistingWebhookRecord
+	deadLetters []daygo.ExistingWebhookDeadLetterRecord
+}
+
+func (r *fakeWebhookRepo) GetWebhooks(context.Context) ([]daygo.ExistingWebhookRecord, error) {
+	return r.webhooks, nil
+}
+
+func (r *fakeWebhookRepo) InsertWebhook(_ context.Context, webhook daygo.WebhookRecord) (daygo.ExistingWebhookRecord, error) {
+	inserted := daygo.ExistingWebhookRecord{
+		WebhookRecord: webhook,
+		ID:            len(r.webhooks) + 1,
+	}
+	r.webhooks = append(r.webhooks, inserted)
+	return inserted, nil
+}
+
+func (r *fakeWebhookRepo) DeleteWebhook(context.Context, int) (daygo.ExistingWebhookRecord, error) {
+	return daygo.ExistingWebhookRecord{}, nil
+}
+
+func (r *fakeWebhookRepo) GetDeadLetters(context.Context) ([]daygo.ExistingWebhookDeadLetterRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deadLetters, nil
+}
+
+func (r *fakeWebhookRepo) InsertDeadLetter(_ context.Context, deadLetter daygo.WebhookDeadLetterRecord) (daygo.ExistingWebhookDeadLetterRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inserted := daygo.ExistingWebhookDeadLetterRecord{
+		WebhookDeadLetterRecord: deadLetter,
+		ID:                      len(r.deadLetters) + 1,
+	}
+	r.deadLetters = append(r.deadLetters, inserted)
+	return inserted, nil
+}
+
+func newTaskEvent(name string) daygo.WebhookEvent {
+	var task daygo.ExistingTaskRecord
+	task.Name = name
+	return daygo.WebhookEvent{
+		Type: daygo.WebhookEventTaskCreated,
+		Task: task,
+		Time: time.Now(),
+	}
+}
+
+func TestWebhookDispatcher_SignsAndFiltersByTag(t *testing.T) {
+	// arrange
+	type received struct {
+		event     daygo.WebhookEvent
+		signature string
+		valid     bool
+	}
+	var mu sync.Mutex
+	var got []received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e daygo.WebhookEvent
+		_ = json.Unmarshal(body, &e)
+		sig := r.Header.Get(daygo.WebhookSignatureHeader)
+		mu.Lock()
+		got = append(got, received{e, sig, sig == daygo.SignWebhookPayload("s3cret", body)})
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	repo := &fakeWebhookRepo{}
+	_, _ = repo.InsertWebhook(context.Background(), daygo.WebhookRecord{
+		URL:    srv.URL,
+		Tags:   []string{"work"},
+		Secret: "s3cret",
+	})
+	d := newWebhookDispatcher(repo, daygo.NoOpLogger{}, 3, time.Millisecond)
+
+	// act
+	d.Dispatch([]daygo.WebhookEvent{
+		newTaskEvent("write report #work"),
+		newTaskEvent("water plants #home"),
+	})
+	d.Wait()
+
+	// assert
+	if len(got) != 1 {
+		t.Fatalf("want 1 delivery, got %d", len(got))
+	}
+	if got[0].event.Task.Name != "write report #work" {
+		t.Fatalf("want %q, got %q", "write report #work", got[0].event.Task.Name)
+	}
+	if !got[0].valid {
+		t.Fatalf("want valid signature, got %q", got[0].signature)
+	}
+}
+
+func TestWebhookDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	// arrange
+	var mu sync.Mutex
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	repo := &fakeWebhookRepo{}
+	_, _ = repo.InsertWebhook(context.Background(), daygo.WebhookRecord{URL: srv.URL})
+	d := newWebhookDispatcher(repo, daygo.NoOpLogger{}, 3, time.Millisecond)
+
+	// act
+	d.Dispatch([]daygo.WebhookEvent{newTaskEvent("deploy")})
+	d.Wait()
+
+	// assert
+	if attempts != 3 {
+		t.Fatalf("want 3 attempts, got %d", attempts)
+	}
+	deadLetters, _ := repo.GetDeadLetters(context.Background())
+	if len(deadLetters) != 1 {
+		t.Fatalf("want 1 dead letter, got %d", len(deadLetters))
+	}
+	if dl := deadLetters[0]; dl.WebhookID != 1 || dl.Attempts != 3 || dl.Event != daygo.WebhookEventTaskCreated {
+		t.Fatalf("want dead letter for webhook 1 after 3 attempts, got %+v", dl)
+	}
+}
+
+func TestWebhookDispatcher_RecoversOnRetry(t *testing.T) {
+	// arrange
+	var mu sync.Mutex
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	repo := &fakeWebhookRepo{}
+	_, _ = repo.InsertWebhook(context.Background(), daygo.WebhookRecord{URL: srv.URL})
+	d := newWebhookDispatcher(repo, daygo.NoOpLogger{}, 3, time.Millisecond)
+
+	// act
+	d.Dispatch([]daygo.WebhookEvent{newTaskEvent("deploy")})
+	d.Wait()
+
+	// assert
+	if attempts != 2 {
+		t.Fatalf("want 2 attempts, got %d", attempts)
+	}
+	if deadLetters, _ := repo.GetDeadLetters(context.Background()); len(deadLetters) != 0 {
+		t.Fatalf("want no dead letters, got %d", len(deadLetters))
+	}
+}
@@ -4,16 +4,18 @@ go 1.25.1
 
 require (
 	github.com/go-logfmt/logfmt v0.6.0 // indirect
-	modernc.org/sqlite v1.39.1 // indirect
+	modernc.org/sqlite v1.39.1
 )
 
 require (
+	github.com/Thiht/transactor v1.1.0
 	github.com/benjamonnguyen/deadsimple/config v0.0.0
 	github.com/benjamonnguyen/deadsimple/database v0.0.0
 	github.com/charmbracelet/bubbles v0.21.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/charmbracelet/log v0.4.2
+	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 )
 
@@ -22,7 +24,6 @@ replace github.com/benjamonnguyen/deadsimple/database v0.0.0 => ../deadsimple/da
 replace github.com/benjamonnguyen/deadsimple/config v0.0.0 => ../deadsimple/config
 
 require (
-	github.com/Thiht/transactor v1.1.0 // indirect
 	github.com/atotto/clipboard v0.1.4 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/charmbracelet/colorprofile v0.2.3-0.20250311203215-f60798e515dc // indirect
@@ -32,7 +33,6 @@ require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/erikgeiser/coninput v0.0.0-20211004153227-1c3628e74d0f // indirect
 	github.com/golang-migrate/migrate/v4 v4.19.0 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
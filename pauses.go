@@ -0,0 +1,28 @@
+package daygo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PauseRepo interface {
+	GetByTaskID(context.Context, uuid.UUID) ([]ExistingPauseRecord, error)
+	InsertPause(context.Context, PauseRecord) (ExistingPauseRecord, error)
+	UpdatePause(context.Context, int, PauseRecord) (ExistingPauseRecord, error)
+	DeleteByTaskID(context.Context, uuid.UUID) ([]ExistingPauseRecord, error)
+}
+
+// PauseRecord represents an interruption of a task, excluded from its duration
+type PauseRecord struct {
+	TaskID    uuid.UUID
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+type ExistingPauseRecord struct {
+	PauseRecord
+	ID        int
+	CreatedAt time.Time
+}
@@ -0,0 +1,218 @@
+package daygo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecurrenceRepo interface {
+	GetRecurrence(context.Context, uuid.UUID) (ExistingRecurrenceRecord, error)
+	// GetAllRecurrences returns the recurrences that aren't deleted
+	GetAllRecurrences(context.Context) ([]ExistingRecurrenceRecord, error)
+	// GetRecurrencesByUpdateTime returns recurrences updated within range,
+	// including deleted ones so that their deletion syncs
+	GetRecurrencesByUpdateTime(ctx context.Context, min, max time.Time) ([]ExistingRecurrenceRecord, error)
+
+	InsertRecurrence(context.Context, RecurrenceRecord) (ExistingRecurrenceRecord, error)
+	UpdateRecurrence(context.Context, uuid.UUID, RecurrenceRecord) (ExistingRecurrenceRecord, error)
+	// DeleteRecurrence marks the recurrence as deleted; returns sqlite.ErrNotFound
+	// if it doesn't exist or is already deleted
+	DeleteRecurrence(context.Context, uuid.UUID) (ExistingRecurrenceRecord, error)
+	// SyncRecurrence inserts or overwrites a recurrence, preserving its ID and
+	// timestamps. A deleted recurrence stays deleted.
+	SyncRecurrence(context.Context, ExistingRecurrenceRecord) (ExistingRecurrenceRecord, error)
+}
+
+// RecurrenceRecord describes a task that is queued on a schedule
+type RecurrenceRecord struct {
+	Name   string
+	Spec   string
+	NextAt time.Time
+}
+
+type ExistingRecurrenceRecord struct {
+	RecurrenceRecord
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set once the recurrence is deleted
+	DeletedAt time.Time
+}
+
+// Supersedes reports whether r should overwrite existing when synced:
+// it was updated later, or it deletes a recurrence that isn't deleted yet
+func (r ExistingRecurrenceRecord) Supersedes(existing ExistingRecurrenceRecord) bool {
+	return r.UpdatedAt.After(existing.UpdatedAt) || (!r.DeletedAt.IsZero() && existing.DeletedAt.IsZero())
+}
+
+type ClaimRecurrenceRequest struct {
+	RecurrenceID uuid.UUID `json:"recurrence_id"`
+	OccurrenceAt time.Time `json:"occurrence_at"`
+}
+
+type ClaimRecurrenceResponse struct {
+	Claimed    bool                     `json:"claimed"`
+	Recurrence ExistingRecurrenceRecord `json:"recurrence"`
+}
+
+type RecurrenceKind int
+
+const (
+	_ RecurrenceKind = iota
+	RecurrenceDaily
+	RecurrenceWeekdays
+	RecurrenceEvery  // every Interval days
+	RecurrenceWeekly // weekly on Weekdays
+)
+
+// RecurrenceSpec is a parsed RecurrenceRecord.Spec. Occurrences fall on the
+// start of the day in local time.
+type RecurrenceSpec struct {
+	Kind     RecurrenceKind
+	Interval int
+	Weekdays []time.Weekday
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const RecurrenceSpecUsage = "daily|weekdays|every <N>d|weekly <mon,tue,...>"
+
+// SplitRecurrenceSpec parses the spec at the start of s and returns the remainder
+func SplitRecurrenceSpec(s string) (RecurrenceSpec, string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return RecurrenceSpec{}, "", fmt.Errorf("provide spec: %s", RecurrenceSpecUsage)
+	}
+
+	n := 1
+	if fields[0] == "every" || fields[0] == "weekly" {
+		n = 2
+	}
+	if len(fields) < n {
+		return RecurrenceSpec{}, "", fmt.Errorf("invalid spec %q: %s", s, RecurrenceSpecUsage)
+	}
+
+	spec, err := ParseRecurrenceSpec(strings.Join(fields[:n], " "))
+	if err != nil {
+		return RecurrenceSpec{}, "", err
+	}
+	return spec, strings.Join(fields[n:], " "), nil
+}
+
+func ParseRecurrenceSpec(s string) (RecurrenceSpec, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return RecurrenceSpec{}, fmt.Errorf("provide spec: %s", RecurrenceSpecUsage)
+	}
+
+	switch {
+	case fields[0] == "daily" && len(fields) == 1:
+		return RecurrenceSpec{Kind: RecurrenceDaily}, nil
+	case fields[0] == "weekdays" && len(fields) == 1:
+		return RecurrenceSpec{Kind: RecurrenceWeekdays}, nil
+	case fields[0] == "every" && len(fields) == 2:
+		interval, err := strconv.Atoi(strings.TrimSuffix(fields[1], "d"))
+		if err != nil || interval < 1 {
+			return RecurrenceSpec{}, fmt.Errorf("invalid interval %q: expected <N>d", fields[1])
+		}
+		return RecurrenceSpec{Kind: RecurrenceEvery, Interval: interval}, nil
+	case fields[0] == "weekly" && len(fields) == 2:
+		var weekdays []time.Weekday
+		for name := range strings.SplitSeq(fields[1], ",") {
+			wd, ok := weekdayNames[name]
+			if !ok {
+				return RecurrenceSpec{}, fmt.Errorf("invalid weekday %q", name)
+			}
+			if !slices.Contains(weekdays, wd) {
+				weekdays = append(weekdays, wd)
+			}
+		}
+		slices.Sort(weekdays)
+		return RecurrenceSpec{Kind: RecurrenceWeekly, Weekdays: weekdays}, nil
+	}
+
+	return RecurrenceSpec{}, fmt.Errorf("invalid spec %q: %s", s, RecurrenceSpecUsage)
+}
+
+func (s RecurrenceSpec) String() string {
+	switch s.Kind {
+	case RecurrenceDaily:
+		return "daily"
+	case RecurrenceWeekdays:
+		return "weekdays"
+	case RecurrenceEvery:
+		return fmt.Sprintf("every %dd", s.Interval)
+	case RecurrenceWeekly:
+		names := make([]string, 0, len(s.Weekdays))
+		for _, wd := range s.Weekdays {
+			names = append(names, strings.ToLower(wd.String()[:3]))
+		}
+		return "weekly " + strings.Join(names, ",")
+	default:
+		return ""
+	}
+}
+
+// First returns the first occurrence on or after the start of now's day
+func (s RecurrenceSpec) First(now time.Time) time.Time {
+	today := startOfDay(now)
+	if s.Kind == RecurrenceEvery {
+		return today
+	}
+	return s.Next(today.AddDate(0, 0, -1))
+}
+
+// Next returns the first occurrence after the day of t
+func (s RecurrenceSpec) Next(t time.Time) time.Time {
+	day := startOfDay(t)
+	if s.Kind == RecurrenceEvery {
+		return day.AddDate(0, 0, s.Interval)
+	}
+
+	for range 7 {
+		day = day.AddDate(0, 0, 1)
+		if s.matches(day.Weekday()) {
+			return day
+		}
+	}
+	return day
+}
+
+// Advance returns the first occurrence following occurrence that is after now
+func (s RecurrenceSpec) Advance(occurrence, now time.Time) time.Time {
+	next := s.Next(occurrence)
+	for !next.After(now) {
+		next = s.Next(next)
+	}
+	return next
+}
+
+func (s RecurrenceSpec) matches(wd time.Weekday) bool {
+	switch s.Kind {
+	case RecurrenceWeekdays:
+		return wd != time.Saturday && wd != time.Sunday
+	case RecurrenceWeekly:
+		return slices.Contains(s.Weekdays, wd)
+	default:
+		return true
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
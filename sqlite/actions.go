@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
+)
+
+type taskActionEntity struct {
+	ID        int
+	TaskID    string
+	Name      string
+	Action    string
+	CreatedAt int64
+}
+
+// taskActionRepo
+type taskActionRepo struct {
+	dbGetter txStdLib.DBGetter
+	l        daygo.Logger
+}
+
+var _ daygo.TaskActionRepo = (*taskActionRepo)(nil)
+
+func NewTaskActionRepo(dbGetter txStdLib.DBGetter, logger daygo.Logger) daygo.TaskActionRepo {
+	return &taskActionRepo{
+		l:        logger,
+		dbGetter: dbGetter,
+	}
+}
+
+func (r *taskActionRepo) InsertTaskAction(ctx context.Context, action daygo.TaskActionRecord) (daygo.ExistingTaskActionRecord, error) {
+	if action.TaskID == uuid.Nil {
+		return daygo.ExistingTaskActionRecord{}, fmt.Errorf("provide required field 'TaskID'")
+	}
+	if action.Action == "" {
+		return daygo.ExistingTaskActionRecord{}, fmt.Errorf("provide required field 'Action'")
+	}
+
+	existingRecord := daygo.ExistingTaskActionRecord{
+		TaskActionRecord: action,
+		CreatedAt:        time.Now(),
+	}
+	e := mapToTaskActionEntity(existingRecord)
+
+	query := "INSERT INTO task_actions (task_id, name, action, created_at) VALUES (?, ?, ?, ?)"
+	r.l.Debug("creating task action", "query", query, "entity", e)
+	result, err := r.dbGetter(ctx).ExecContext(ctx, query, e.TaskID, e.Name, e.Action, e.CreatedAt)
+	if err != nil {
+		return daygo.ExistingTaskActionRecord{}, err
+	}
+
+	insertedID, err := result.LastInsertId()
+	if err != nil {
+		return daygo.ExistingTaskActionRecord{}, err
+	}
+	existingRecord.ID = int(insertedID)
+
+	return existingRecord, nil
+}
+
+func (r *taskActionRepo) CountByAction(ctx context.Context, min, max time.Time, groupBy daygo.StatsGroup) ([]daygo.TaskActionCountRecord, error) {
+	if min.IsZero() || max.IsZero() {
+		return nil, fmt.Errorf("provide min and max")
+	}
+
+	cte, err := groupedCTE("SELECT id, name, created_at AS at, action FROM task_actions WHERE created_at BETWEEN ? AND ?", groupBy)
+	if err != nil {
+		return nil, err
+	}
+	query := cte + " SELECT grp, action, COUNT(*) FROM grouped GROUP BY grp, action ORDER BY grp, action"
+	args := []any{min.Unix(), max.Unix()}
+
+	db := r.dbGetter(ctx)
+	r.l.Debug("CountByAction", "query", query, "args", args)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var counts []daygo.TaskActionCountRecord
+	for rows.Next() {
+		var c daygo.TaskActionCountRecord
+		var action string
+		if err := rows.Scan(&c.Group, &action, &c.Count); err != nil {
+			return nil, err
+		}
+		c.Action = daygo.TaskAction(action)
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (r *taskActionRepo) DeleteLastTaskAction(ctx context.Context, taskID uuid.UUID, action daygo.TaskAction) (daygo.ExistingTaskActionRecord, error) {
+	if taskID == uuid.Nil {
+		return daygo.ExistingTaskActionRecord{}, fmt.Errorf("provide taskID")
+	}
+
+	db := r.dbGetter(ctx)
+	var e taskActionEntity
+	query := "SELECT id, task_id, name, action, created_at FROM task_actions WHERE task_id = ? AND action = ? ORDER BY id DESC LIMIT 1"
+	row := db.QueryRowContext(ctx, query, taskID.String(), string(action))
+	if err := row.Scan(&e.ID, &e.TaskID, &e.Name, &e.Action, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return daygo.ExistingTaskActionRecord{}, fmt.Errorf("failed to extract task action: %w", ErrNotFound)
+		}
+		return daygo.ExistingTaskActionRecord{}, err
+	}
+
+	query = "DELETE FROM task_actions WHERE id = ?"
+	r.l.Debug("deleting task action", "query", query, "id", e.ID)
+	if _, err := db.ExecContext(ctx, query, e.ID); err != nil {
+		return daygo.ExistingTaskActionRecord{}, err
+	}
+
+	return daygo.ExistingTaskActionRecord{
+		TaskActionRecord: daygo.TaskActionRecord{
+			TaskID: taskID,
+			Name:   e.Name,
+			Action: daygo.TaskAction(e.Action),
+		},
+		ID:        e.ID,
+		CreatedAt: time.Unix(e.CreatedAt, 0),
+	}, nil
+}
+
+func mapToTaskActionEntity(action daygo.ExistingTaskActionRecord) taskActionEntity {
+	return taskActionEntity{
+		ID:        action.ID,
+		TaskID:    action.TaskID.String(),
+		Name:      action.Name,
+		Action:    string(action.Action),
+		CreatedAt: action.CreatedAt.Unix(),
+	}
+}
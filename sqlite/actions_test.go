@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
+)
+
+func TestCountByAction(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	repo := NewTaskActionRepo(newTestDB(t), daygo.NoOpLogger{})
+	actions := []daygo.TaskActionRecord{
+		{TaskID: uuid.New(), Name: "triage #work #ops", Action: daygo.TaskActionSkip},
+		{TaskID: uuid.New(), Name: "triage #work", Action: daygo.TaskActionSkip},
+		{TaskID: uuid.New(), Name: "call mom", Action: daygo.TaskActionDiscard},
+	}
+	for _, a := range actions {
+		if _, err := repo.InsertTaskAction(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+
+	// act
+	got, err := repo.CountByAction(ctx, now.Add(-time.Minute), now.Add(time.Minute), daygo.StatsGroupTag)
+
+	// assert
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	want := []daygo.TaskActionCountRecord{
+		{Group: "", Action: daygo.TaskActionDiscard, Count: 1},
+		{Group: "ops", Action: daygo.TaskActionSkip, Count: 1},
+		{Group: "work", Action: daygo.TaskActionSkip, Count: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %+v, got %+v", want, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
+)
+
+const (
+	SelectAllPauses = "SELECT id, task_id, started_at, ended_at, created_at FROM pauses"
+)
+
+type pauseEntity struct {
+	ID        int
+	TaskID    string
+	StartedAt int64
+	EndedAt   sql.NullInt64
+	CreatedAt int64
+}
+
+// pauseRepo
+type pauseRepo struct {
+	dbGetter txStdLib.DBGetter
+	l        daygo.Logger
+}
+
+var _ daygo.PauseRepo = (*pauseRepo)(nil)
+
+func NewPauseRepo(dbGetter txStdLib.DBGetter, logger daygo.Logger) daygo.PauseRepo {
+	return &pauseRepo{
+		l:        logger,
+		dbGetter: dbGetter,
+	}
+}
+
+func (r *pauseRepo) GetByTaskID(ctx context.Context, taskID uuid.UUID) ([]daygo.ExistingPauseRecord, error) {
+	if taskID == uuid.Nil {
+		return nil, fmt.Errorf("provide taskID")
+	}
+
+	db := r.dbGetter(ctx)
+	rows, err := db.QueryContext(
+		ctx,
+		fmt.Sprintf("%s WHERE task_id=? ORDER BY started_at", SelectAllPauses), taskID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var pauses []daygo.ExistingPauseRecord
+	for rows.Next() {
+		pause, err := extractPause(rows)
+		if err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+	return pauses, rows.Err()
+}
+
+func (r *pauseRepo) getPause(ctx context.Context, id int) (daygo.ExistingPauseRecord, error) {
+	if id == 0 {
+		return daygo.ExistingPauseRecord{}, fmt.Errorf("provide id")
+	}
+
+	db := r.dbGetter(ctx)
+	row := db.QueryRowContext(
+		ctx,
+		fmt.Sprintf("%s WHERE id=?", SelectAllPauses), id,
+	)
+
+	return extractPause(row)
+}
+
+func (r *pauseRepo) InsertPause(ctx context.Context, pause daygo.PauseRecord) (daygo.ExistingPauseRecord, error) {
+	if pause.TaskID == uuid.Nil {
+		return daygo.ExistingPauseRecord{}, fmt.Errorf("provide required field 'TaskID'")
+	}
+	if pause.StartedAt.IsZero() {
+		return daygo.ExistingPauseRecord{}, fmt.Errorf("provide required field 'StartedAt'")
+	}
+
+	existingRecord := daygo.ExistingPauseRecord{
+		PauseRecord: pause,
+		CreatedAt:   time.Now(),
+	}
+	e := mapToPauseEntity(existingRecord)
+
+	query := "INSERT INTO pauses (task_id, started_at, ended_at, created_at) VALUES (?, ?, ?, ?)"
+	r.l.Debug("creating pause", "query", query, "entity", e)
+	result, err := r.dbGetter(ctx).ExecContext(ctx, query, e.TaskID, e.StartedAt, e.EndedAt, e.CreatedAt)
+	if err != nil {
+		return daygo.ExistingPauseRecord{}, err
+	}
+
+	insertedID, err := result.LastInsertId()
+	if err != nil {
+		return daygo.ExistingPauseRecord{}, err
+	}
+	existingRecord.ID = int(insertedID)
+
+	return existingRecord, nil
+}
+
+func (r *pauseRepo) UpdatePause(ctx context.Context, id int, updated daygo.PauseRecord) (daygo.ExistingPauseRecord, error) {
+	existing, err := r.getPause(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+
+	existing.PauseRecord = updated
+	e := mapToPauseEntity(existing)
+
+	query := "UPDATE pauses SET task_id = ?, started_at = ?, ended_at = ? WHERE id = ?"
+	r.l.Debug("updating pause", "query", query, "entity", e)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, e.TaskID, e.StartedAt, e.EndedAt, e.ID); err != nil {
+		return daygo.ExistingPauseRecord{}, err
+	}
+
+	return existing, nil
+}
+
+func (r *pauseRepo) DeleteByTaskID(ctx context.Context, taskID uuid.UUID) ([]daygo.ExistingPauseRecord, error) {
+	existing, err := r.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "DELETE FROM pauses WHERE task_id = ?"
+	r.l.Debug("deleting pauses", "query", query, "taskID", taskID)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, taskID.String()); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func extractPause(s scannable) (daygo.ExistingPauseRecord, error) {
+	var e pauseEntity
+	if err := s.Scan(&e.ID, &e.TaskID, &e.StartedAt, &e.EndedAt, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return daygo.ExistingPauseRecord{}, fmt.Errorf("failed to extract pause: %w", ErrNotFound)
+		}
+		return daygo.ExistingPauseRecord{}, err
+	}
+
+	return mapToExistingPauseRecord(e), nil
+}
+
+func mapToPauseEntity(pause daygo.ExistingPauseRecord) pauseEntity {
+	e := pauseEntity{
+		ID:        pause.ID,
+		TaskID:    pause.TaskID.String(),
+		StartedAt: pause.StartedAt.Unix(),
+		CreatedAt: pause.CreatedAt.Unix(),
+	}
+	if !pause.EndedAt.IsZero() {
+		e.EndedAt = sql.NullInt64{
+			Valid: true,
+			Int64: pause.EndedAt.Unix(),
+		}
+	}
+	return e
+}
+
+func mapToExistingPauseRecord(e pauseEntity) daygo.ExistingPauseRecord {
+	var endedAt time.Time
+	if e.EndedAt.Valid {
+		endedAt = time.Unix(e.EndedAt.Int64, 0).Local()
+	}
+
+	taskID, _ := uuid.Parse(e.TaskID)
+
+	return daygo.ExistingPauseRecord{
+		ID:        e.ID,
+		CreatedAt: time.Unix(e.CreatedAt, 0).Local(),
+		PauseRecord: daygo.PauseRecord{
+			TaskID:    taskID,
+			StartedAt: time.Unix(e.StartedAt, 0).Local(),
+			EndedAt:   endedAt,
+		},
+	}
+}
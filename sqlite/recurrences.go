@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+
+	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
+)
+
+const (
+	SelectAllRecurrences = "SELECT id, name, spec, next_at, created_at, updated_at, deleted_at FROM recurrences"
+)
+
+type recurrenceEntity struct {
+	ID        string
+	Name      string
+	Spec      string
+	NextAt    int64
+	CreatedAt int64
+	UpdatedAt int64
+	DeletedAt sql.NullInt64
+}
+
+// recurrenceRepo
+type recurrenceRepo struct {
+	dbGetter txStdLib.DBGetter
+	l        daygo.Logger
+}
+
+var _ daygo.RecurrenceRepo = (*recurrenceRepo)(nil)
+
+func NewRecurrenceRepo(dbGetter txStdLib.DBGetter, logger daygo.Logger) daygo.RecurrenceRepo {
+	return &recurrenceRepo{
+		l:        logger,
+		dbGetter: dbGetter,
+	}
+}
+
+func (r *recurrenceRepo) GetRecurrence(ctx context.Context, id uuid.UUID) (daygo.ExistingRecurrenceRecord, error) {
+	if id == uuid.Nil {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("provide id")
+	}
+
+	db := r.dbGetter(ctx)
+	row := db.QueryRowContext(
+		ctx,
+		fmt.Sprintf("%s WHERE id=?", SelectAllRecurrences), id.String(),
+	)
+
+	return extractRecurrence(row)
+}
+
+func (r *recurrenceRepo) GetAllRecurrences(ctx context.Context) ([]daygo.ExistingRecurrenceRecord, error) {
+	db := r.dbGetter(ctx)
+	rows, err := db.QueryContext(ctx, SelectAllRecurrences+" WHERE deleted_at IS NULL ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	return extractRecurrences(rows)
+}
+
+func (r *recurrenceRepo) GetRecurrencesByUpdateTime(ctx context.Context, min, max time.Time) ([]daygo.ExistingRecurrenceRecord, error) {
+	query := SelectAllRecurrences
+	var args []any
+
+	if !min.IsZero() && !max.IsZero() {
+		query += " WHERE updated_at BETWEEN ? AND ?"
+		args = append(args, min.Unix(), max.Unix())
+	} else if !min.IsZero() {
+		query += " WHERE updated_at >= ?"
+		args = append(args, min.Unix())
+	} else if !max.IsZero() {
+		query += " WHERE updated_at <= ?"
+		args = append(args, max.Unix())
+	}
+
+	db := r.dbGetter(ctx)
+	r.l.Debug("GetRecurrencesByUpdateTime", "query", query, "args", args)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractRecurrences(rows)
+}
+
+func (r *recurrenceRepo) InsertRecurrence(ctx context.Context, recurrence daygo.RecurrenceRecord) (daygo.ExistingRecurrenceRecord, error) {
+	if recurrence.Name == "" {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("provide required field 'Name'")
+	}
+	if recurrence.Spec == "" {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("provide required field 'Spec'")
+	}
+
+	now := time.Now()
+	existingRecord := daygo.ExistingRecurrenceRecord{
+		RecurrenceRecord: recurrence,
+		ID:               uuid.New(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	e := mapToRecurrenceEntity(existingRecord)
+
+	args := []any{
+		e.ID,
+		e.Name,
+		e.Spec,
+		e.NextAt,
+		e.CreatedAt,
+		e.UpdatedAt,
+	}
+	query := "INSERT INTO recurrences (id, name, spec, next_at, created_at, updated_at) VALUES " + generateParameters(len(args))
+	r.l.Debug("creating recurrence", "query", query, "args", args)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, args...); err != nil {
+		return daygo.ExistingRecurrenceRecord{}, err
+	}
+
+	return existingRecord, nil
+}
+
+func (r *recurrenceRepo) UpdateRecurrence(ctx context.Context, id uuid.UUID, updated daygo.RecurrenceRecord) (daygo.ExistingRecurrenceRecord, error) {
+	existing, err := r.GetRecurrence(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+
+	existing.RecurrenceRecord = updated
+	existing.UpdatedAt = time.Now()
+	e := mapToRecurrenceEntity(existing)
+
+	query := "UPDATE recurrences SET name = ?, spec = ?, next_at = ?, updated_at = ? WHERE id = ?"
+	args := []any{
+		e.Name,
+		e.Spec,
+		e.NextAt,
+		e.UpdatedAt,
+		e.ID,
+	}
+	r.l.Debug("updating recurrence", "query", query, "args", args)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, args...); err != nil {
+		return daygo.ExistingRecurrenceRecord{}, err
+	}
+
+	return existing, nil
+}
+
+func (r *recurrenceRepo) DeleteRecurrence(ctx context.Context, id uuid.UUID) (daygo.ExistingRecurrenceRecord, error) {
+	existing, err := r.GetRecurrence(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+	if !existing.DeletedAt.IsZero() {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("recurrence already deleted: %w", ErrNotFound)
+	}
+
+	now := time.Now()
+	existing.DeletedAt = now
+	existing.UpdatedAt = now
+	e := mapToRecurrenceEntity(existing)
+
+	query := "UPDATE recurrences SET deleted_at = ?, updated_at = ? WHERE id = ?"
+	args := []any{
+		e.DeletedAt,
+		e.UpdatedAt,
+		e.ID,
+	}
+	r.l.Debug("deleting recurrence", "query", query, "args", args)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, args...); err != nil {
+		return daygo.ExistingRecurrenceRecord{}, err
+	}
+
+	return existing, nil
+}
+
+func (r *recurrenceRepo) SyncRecurrence(ctx context.Context, recurrence daygo.ExistingRecurrenceRecord) (daygo.ExistingRecurrenceRecord, error) {
+	if recurrence.ID == uuid.Nil {
+		return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("provide id")
+	}
+	e := mapToRecurrenceEntity(recurrence)
+
+	args := []any{
+		e.ID,
+		e.Name,
+		e.Spec,
+		e.NextAt,
+		e.CreatedAt,
+		e.UpdatedAt,
+		e.DeletedAt,
+	}
+	query := "INSERT INTO recurrences (id, name, spec, next_at, created_at, updated_at, deleted_at) VALUES " + generateParameters(len(args)) +
+		" ON CONFLICT(id) DO UPDATE SET name = excluded.name, spec = excluded.spec, next_at = excluded.next_at, updated_at = excluded.updated_at," +
+		" deleted_at = COALESCE(recurrences.deleted_at, excluded.deleted_at)"
+	r.l.Debug("syncing recurrence", "query", query, "args", args)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, args...); err != nil {
+		return daygo.ExistingRecurrenceRecord{}, err
+	}
+
+	return recurrence, nil
+}
+
+func extractRecurrences(rows *sql.Rows) ([]daygo.ExistingRecurrenceRecord, error) {
+	defer rows.Close() //nolint:errcheck
+
+	var recurrences []daygo.ExistingRecurrenceRecord
+	for rows.Next() {
+		recurrence, err := extractRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	return recurrences, rows.Err()
+}
+
+func extractRecurrence(s scannable) (daygo.ExistingRecurrenceRecord, error) {
+	var e recurrenceEntity
+	if err := s.Scan(&e.ID, &e.Name, &e.Spec, &e.NextAt, &e.CreatedAt, &e.UpdatedAt, &e.DeletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return daygo.ExistingRecurrenceRecord{}, fmt.Errorf("failed to extract recurrence: %w", ErrNotFound)
+		}
+		return daygo.ExistingRecurrenceRecord{}, err
+	}
+
+	return mapToExistingRecurrenceRecord(e), nil
+}
+
+func mapToRecurrenceEntity(recurrence daygo.ExistingRecurrenceRecord) recurrenceEntity {
+	e := recurrenceEntity{
+		ID:        recurrence.ID.String(),
+		Name:      recurrence.Name,
+		Spec:      recurrence.Spec,
+		NextAt:    recurrence.NextAt.Unix(),
+		CreatedAt: recurrence.CreatedAt.Unix(),
+		UpdatedAt: recurrence.UpdatedAt.Unix(),
+	}
+	if !recurrence.DeletedAt.IsZero() {
+		e.DeletedAt = sql.NullInt64{
+			Int64: recurrence.DeletedAt.Unix(),
+			Valid: true,
+		}
+	}
+	return e
+}
+
+func mapToExistingRecurrenceRecord(e recurrenceEntity) daygo.ExistingRecurrenceRecord {
+	id, _ := uuid.Parse(e.ID)
+	var deletedAt time.Time
+	if e.DeletedAt.Valid {
+		deletedAt = time.Unix(e.DeletedAt.Int64, 0).Local()
+	}
+
+	return daygo.ExistingRecurrenceRecord{
+		ID:        id,
+		CreatedAt: time.Unix(e.CreatedAt, 0).Local(),
+		UpdatedAt: time.Unix(e.UpdatedAt, 0).Local(),
+		DeletedAt: deletedAt,
+		RecurrenceRecord: daygo.RecurrenceRecord{
+			Name:   e.Name,
+			Spec:   e.Spec,
+			NextAt: time.Unix(e.NextAt, 0).Local(),
+		},
+	}
+}
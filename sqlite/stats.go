@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+// groupedCTE returns common table expressions ending in "grouped", which
+// selects the rows of src labeled with their group as grp. src must select
+// id, name and at, the unix time that determines the row's day.
+func groupedCTE(src string, groupBy daygo.StatsGroup) (string, error) {
+	switch groupBy {
+	case daygo.StatsGroupNone:
+		return fmt.Sprintf("WITH src AS (%s), grouped AS (SELECT '' AS grp, src.* FROM src)", src), nil
+	case daygo.StatsGroupDay:
+		return fmt.Sprintf("WITH src AS (%s), grouped AS (SELECT date(at, 'unixepoch', 'localtime') AS grp, src.* FROM src)", src), nil
+	case daygo.StatsGroupTag:
+		// split names on spaces into words, keeping those that are tags
+		return fmt.Sprintf(`WITH RECURSIVE src AS (%s),
+words(id, word, rest) AS (
+	SELECT id, '', name || ' ' FROM src
+	UNION ALL
+	SELECT id, substr(rest, 1, instr(rest, ' ') - 1), substr(rest, instr(rest, ' ') + 1) FROM words WHERE rest != ''
+),
+tags AS (SELECT DISTINCT id, substr(word, 2) AS tag FROM words WHERE word LIKE '#_%%'),
+grouped AS (SELECT COALESCE(tags.tag, '') AS grp, src.* FROM src LEFT JOIN tags ON tags.id = src.id)`, src), nil
+	default:
+		return "", fmt.Errorf("unknown stats group %d", groupBy)
+	}
+}
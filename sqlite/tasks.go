@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	txStdLib "github.com/Thiht/transactor/stdlib"
@@ -15,20 +16,24 @@ import (
 )
 
 const (
-	SelectAll = "SELECT id, name, started_at, ended_at, parent_id, created_at, updated_at, queued_at FROM tasks"
+	SelectAll = "SELECT id, name, started_at, ended_at, parent_id, created_at, updated_at, queued_at, kind, continues_id, pomodoros, time_block_at FROM tasks"
 )
 
 var ErrNotFound = errors.New("not found")
 
 type taskEntity struct {
-	ID        string
-	Name      string
-	StartedAt sql.NullInt64
-	EndedAt   sql.NullInt64
-	CreatedAt int64
-	UpdatedAt int64
-	ParentID  sql.NullString
-	QueuedAt  sql.NullInt64
+	ID          string
+	Name        string
+	StartedAt   sql.NullInt64
+	EndedAt     sql.NullInt64
+	CreatedAt   int64
+	UpdatedAt   int64
+	ParentID    sql.NullString
+	QueuedAt    sql.NullInt64
+	Kind        int
+	ContinuesID sql.NullString
+	Pomodoros   int
+	TimeBlockAt sql.NullInt64
 }
 
 // taskRepo
@@ -150,6 +155,69 @@ func (r *taskRepo) GetByParentID(ctx context.Context, parentID uuid.UUID) ([]day
 	return subtasks, nil
 }
 
+func (r *taskRepo) GetByContinuesID(ctx context.Context, continuesID uuid.UUID) ([]daygo.ExistingTaskRecord, error) {
+	if continuesID == uuid.Nil {
+		return nil, fmt.Errorf("provide continuesID")
+	}
+
+	db := r.dbGetter(ctx)
+	rows, err := db.QueryContext(
+		ctx,
+		fmt.Sprintf("%s WHERE continues_id=? ORDER BY created_at", SelectAll), continuesID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractTasks(rows)
+}
+
+func (r *taskRepo) GetInProgress(ctx context.Context) ([]daygo.ExistingTaskRecord, error) {
+	db := r.dbGetter(ctx)
+	query := fmt.Sprintf("%s WHERE started_at NOTNULL AND ended_at ISNULL AND parent_id ISNULL AND remote = 0 ORDER BY started_at DESC", SelectAll)
+	r.l.Debug("GetInProgress", "query", query)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractTasks(rows)
+}
+
+func (r *taskRepo) GetLastEnded(ctx context.Context) (daygo.ExistingTaskRecord, error) {
+	db := r.dbGetter(ctx)
+	query := fmt.Sprintf("%s WHERE ended_at NOTNULL AND parent_id ISNULL AND kind = ? ORDER BY ended_at DESC LIMIT 1", SelectAll)
+	r.l.Debug("GetLastEnded", "query", query)
+	row := db.QueryRowContext(ctx, query, int(daygo.TaskKindTask))
+
+	t, err := extractTask(row)
+	if err != nil {
+		return daygo.ExistingTaskRecord{}, fmt.Errorf("failed to extract task: %w", err)
+	}
+	return t, nil
+}
+
+func (r *taskRepo) GetRecentNames(ctx context.Context, limit int) ([]string, error) {
+	db := r.dbGetter(ctx)
+	query := "SELECT name FROM tasks WHERE parent_id ISNULL AND kind = ? GROUP BY name ORDER BY MAX(COALESCE(started_at, created_at)) DESC LIMIT ?"
+	r.l.Debug("GetRecentNames", "query", query, "limit", limit)
+	rows, err := db.QueryContext(ctx, query, int(daygo.TaskKindTask), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 func (r *taskRepo) GetByCreateTime(ctx context.Context, min, max time.Time) ([]daygo.ExistingTaskRecord, error) {
 	query := SelectAll
 	var args []any
@@ -214,7 +282,7 @@ func extractTasks(rows *sql.Rows) ([]daygo.ExistingTaskRecord, error) {
 
 func extractTask(s scannable) (daygo.ExistingTaskRecord, error) {
 	var e taskEntity
-	if err := s.Scan(&e.ID, &e.Name, &e.StartedAt, &e.EndedAt, &e.ParentID, &e.CreatedAt, &e.UpdatedAt, &e.QueuedAt); err != nil {
+	if err := s.Scan(&e.ID, &e.Name, &e.StartedAt, &e.EndedAt, &e.ParentID, &e.CreatedAt, &e.UpdatedAt, &e.QueuedAt, &e.Kind, &e.ContinuesID, &e.Pomodoros, &e.TimeBlockAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return daygo.ExistingTaskRecord{}, ErrNotFound
 		}
@@ -229,15 +297,33 @@ func (r *taskRepo) InsertTask(ctx context.Context, task daygo.TaskRecord) (daygo
 		return daygo.ExistingTaskRecord{}, fmt.Errorf("provide required field 'Name'")
 	}
 
-	db := r.dbGetter(ctx)
 	now := time.Now()
-
-	existingRecord := daygo.ExistingTaskRecord{
+	return r.insert(ctx, daygo.ExistingTaskRecord{
 		TaskRecord: task,
 		ID:         uuid.New(),
 		CreatedAt:  now,
 		UpdatedAt:  now,
+	})
+}
+
+func (r *taskRepo) RestoreTask(ctx context.Context, task daygo.ExistingTaskRecord) (daygo.ExistingTaskRecord, error) {
+	if task.Name == "" {
+		return daygo.ExistingTaskRecord{}, fmt.Errorf("provide required field 'Name'")
 	}
+	if task.ID == uuid.Nil {
+		return daygo.ExistingTaskRecord{}, fmt.Errorf("provide id")
+	}
+
+	now := time.Now()
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	task.UpdatedAt = now
+	return r.insert(ctx, task)
+}
+
+func (r *taskRepo) insert(ctx context.Context, existingRecord daygo.ExistingTaskRecord) (daygo.ExistingTaskRecord, error) {
+	db := r.dbGetter(ctx)
 	e := mapToTaskEntity(existingRecord)
 
 	args := []any{
@@ -249,8 +335,12 @@ func (r *taskRepo) InsertTask(ctx context.Context, task daygo.TaskRecord) (daygo
 		e.CreatedAt,
 		e.UpdatedAt,
 		e.QueuedAt,
+		e.Kind,
+		e.ContinuesID,
+		e.Pomodoros,
+		e.TimeBlockAt,
 	}
-	query := "INSERT INTO tasks (id, name, parent_id, started_at, ended_at, created_at, updated_at, queued_at) VALUES " + generateParameters(len(args))
+	query := "INSERT INTO tasks (id, name, parent_id, started_at, ended_at, created_at, updated_at, queued_at, kind, continues_id, pomodoros, time_block_at) VALUES " + generateParameters(len(args))
 	r.l.Debug("creating task", "query", query, "args", args)
 	_, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
@@ -270,12 +360,17 @@ func (r *taskRepo) UpdateTask(ctx context.Context, id uuid.UUID, updated daygo.T
 	existing.UpdatedAt = time.Now()
 	e := mapToTaskEntity(existing)
 
-	query := "UPDATE tasks SET name = ?, started_at = ?, ended_at = ?, queued_at = ?, updated_at = ? WHERE id = ?"
+	query := "UPDATE tasks SET name = ?, parent_id = ?, kind = ?, continues_id = ?, pomodoros = ?, started_at = ?, ended_at = ?, queued_at = ?, time_block_at = ?, updated_at = ?, remote = 0 WHERE id = ?"
 	args := []any{
 		e.Name,
+		e.ParentID,
+		e.Kind,
+		e.ContinuesID,
+		e.Pomodoros,
 		e.StartedAt,
 		e.EndedAt,
 		e.QueuedAt,
+		e.TimeBlockAt,
 		e.UpdatedAt,
 		e.ID,
 	}
@@ -288,6 +383,17 @@ func (r *taskRepo) UpdateTask(ctx context.Context, id uuid.UUID, updated daygo.T
 	return existing, nil
 }
 
+func (r *taskRepo) MarkRemote(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("provide id")
+	}
+
+	query := "UPDATE tasks SET remote = 1 WHERE id = ?"
+	r.l.Debug("marking task remote", "query", query, "id", id)
+	_, err := r.dbGetter(ctx).ExecContext(ctx, query, id.String())
+	return err
+}
+
 func (r *taskRepo) DeleteTasks(ctx context.Context, ids []any) ([]daygo.ExistingTaskRecord, error) {
 	toDelete, err := r.GetTasks(ctx, ids)
 	if err != nil {
@@ -320,6 +426,8 @@ func mapToTaskEntity(task daygo.ExistingTaskRecord) taskEntity {
 	e.CreatedAt = task.CreatedAt.Unix()
 	e.UpdatedAt = task.UpdatedAt.Unix()
 	e.ID = task.ID.String()
+	e.Kind = int(task.Kind)
+	e.Pomodoros = task.Pomodoros
 
 	// Handle ParentID as nullable string
 	if task.ParentID != uuid.Nil {
@@ -329,6 +437,13 @@ func mapToTaskEntity(task daygo.ExistingTaskRecord) taskEntity {
 		}
 	}
 
+	if task.ContinuesID != uuid.Nil {
+		e.ContinuesID = sql.NullString{
+			Valid:  true,
+			String: task.ContinuesID.String(),
+		}
+	}
+
 	if !task.StartedAt.IsZero() {
 		e.StartedAt = sql.NullInt64{
 			Valid: true,
@@ -347,11 +462,17 @@ func mapToTaskEntity(task daygo.ExistingTaskRecord) taskEntity {
 			Int64: task.QueuedAt.Unix(),
 		}
 	}
+	if !task.TimeBlockAt.IsZero() {
+		e.TimeBlockAt = sql.NullInt64{
+			Valid: true,
+			Int64: task.TimeBlockAt.Unix(),
+		}
+	}
 	return e
 }
 
 func mapToExistingTaskRecord(e taskEntity) daygo.ExistingTaskRecord {
-	var startedAt, endedAt, queuedAt time.Time
+	var startedAt, endedAt, queuedAt, timeBlockAt time.Time
 	if e.StartedAt.Valid {
 		startedAt = time.Unix(e.StartedAt.Int64, 0).Local()
 	}
@@ -361,6 +482,9 @@ func mapToExistingTaskRecord(e taskEntity) daygo.ExistingTaskRecord {
 	if e.QueuedAt.Valid {
 		queuedAt = time.Unix(e.QueuedAt.Int64, 0).Local()
 	}
+	if e.TimeBlockAt.Valid {
+		timeBlockAt = time.Unix(e.TimeBlockAt.Int64, 0).Local()
+	}
 
 	// Parse UUID for ID
 	id, _ := uuid.Parse(e.ID)
@@ -371,16 +495,111 @@ func mapToExistingTaskRecord(e taskEntity) daygo.ExistingTaskRecord {
 		parentID, _ = uuid.Parse(e.ParentID.String)
 	}
 
+	var continuesID uuid.UUID
+	if e.ContinuesID.Valid && e.ContinuesID.String != "" {
+		continuesID, _ = uuid.Parse(e.ContinuesID.String)
+	}
+
 	return daygo.ExistingTaskRecord{
 		ID:        id,
 		CreatedAt: time.Unix(e.CreatedAt, 0).Local(),
 		UpdatedAt: time.Unix(e.UpdatedAt, 0).Local(),
 		TaskRecord: daygo.TaskRecord{
-			Name:      e.Name,
-			ParentID:  parentID,
-			StartedAt: startedAt,
-			EndedAt:   endedAt,
-			QueuedAt:  queuedAt,
+			Name:        e.Name,
+			ParentID:    parentID,
+			Kind:        daygo.TaskKind(e.Kind),
+			ContinuesID: continuesID,
+			Pomodoros:   e.Pomodoros,
+			StartedAt:   startedAt,
+			EndedAt:     endedAt,
+			QueuedAt:    queuedAt,
+			TimeBlockAt: timeBlockAt,
 		},
 	}
 }
+
+func (r *taskRepo) GetStats(ctx context.Context, min, max time.Time, groupBy daygo.StatsGroup) ([]daygo.TaskStatsRecord, error) {
+	if min.IsZero() || max.IsZero() {
+		return nil, fmt.Errorf("provide min and max")
+	}
+
+	// pauses are clipped to the task as in Task.PausedDuration
+	src := `SELECT t.id, t.name, t.started_at AS at,
+	t.ended_at - t.started_at - COALESCE((
+		SELECT SUM(MAX(MIN(COALESCE(p.ended_at, t.ended_at), t.ended_at) - MAX(p.started_at, t.started_at), 0))
+		FROM pauses p WHERE p.task_id = t.id
+	), 0) AS duration
+FROM tasks t
+WHERE t.parent_id ISNULL AND t.kind = ? AND t.ended_at NOTNULL AND t.started_at BETWEEN ? AND ?`
+	cte, err := groupedCTE(src, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	query := cte + " SELECT grp, COUNT(*), COALESCE(SUM(duration), 0) FROM grouped GROUP BY grp ORDER BY grp"
+	args := []any{int(daygo.TaskKindTask), min.Unix(), max.Unix()}
+
+	db := r.dbGetter(ctx)
+	r.l.Debug("GetStats", "query", query, "args", args)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var stats []daygo.TaskStatsRecord
+	for rows.Next() {
+		var s daygo.TaskStatsRecord
+		var tracked int64
+		if err := rows.Scan(&s.Group, &s.Count, &tracked); err != nil {
+			return nil, err
+		}
+		s.Tracked = time.Duration(tracked) * time.Second
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *taskRepo) Search(ctx context.Context, query string, limit int) ([]daygo.ExistingTaskRecord, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, fmt.Errorf("provide query")
+	}
+
+	// rank children's matches as their parent's, keeping the best
+	q := fmt.Sprintf(`%s JOIN (
+	SELECT COALESCE(c.parent_id, c.id) AS task_id, MIN(f.rank) AS rank
+	FROM tasks_fts f JOIN tasks c ON c.id = f.task_id
+	WHERE tasks_fts MATCH ?
+	GROUP BY 1
+) m ON id = m.task_id
+WHERE parent_id ISNULL
+ORDER BY m.rank, started_at DESC
+LIMIT ?`, SelectAll)
+
+	db := r.dbGetter(ctx)
+	r.l.Debug("Search", "query", q, "match", match, "limit", limit)
+	rows, err := db.QueryContext(ctx, q, match, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractTasks(rows)
+}
+
+// ftsQuery quotes each word of query so that FTS5 syntax such as '-' and ':'
+// is matched literally. The last word matches as a prefix.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, w := range strings.Fields(query) {
+		w = strings.Trim(w, "#")
+		if w == "" {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(w, `"`, `""`)+`"`)
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	terms[len(terms)-1] += "*"
+	return strings.Join(terms, " ")
+}
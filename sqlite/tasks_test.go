@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+	"github.com/benjamonnguyen/daygo"
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// newTestDB returns a getter for an in-memory database with daygo's
+// migrations applied
+func newTestDB(t *testing.T) txStdLib.DBGetter {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each connection has its own in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	files, err := filepath.Glob("../cmd/daygo/migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(b)); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+	}
+
+	_, dbGetter := txStdLib.NewTransactor(db, txStdLib.NestedTransactionsSavepoints)
+	return dbGetter
+}
+
+// insertTestTask inserts a task started at the given time
+func insertTestTask(t *testing.T, repo daygo.TaskRepo, name string, parentID uuid.UUID, at time.Time) daygo.ExistingTaskRecord {
+	t.Helper()
+	inserted, err := repo.InsertTask(context.Background(), daygo.TaskRecord{
+		Name:      name,
+		ParentID:  parentID,
+		StartedAt: at,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inserted
+}
+
+func TestFtsQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "words", query: "write report", want: `"write" "report"*`},
+		{name: "tags", query: "#work", want: `"work"*`},
+		{name: "syntax", query: "code-review: NOT", want: `"code-review:" "NOT"*`},
+		{name: "quotes", query: `say "hi"`, want: `"say" """hi"""*`},
+		{name: "empty", query: " # ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got := ftsQuery(tt.query)
+
+			// assert
+			if got != tt.want {
+				t.Fatalf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewTaskRepo(db, daygo.NoOpLogger{})
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	deletedFirst := insertTestTask(t, repo, "scratch memo", uuid.Nil, start.Add(-time.Minute))
+	report := insertTestTask(t, repo, "write report", uuid.Nil, start)
+	insertTestTask(t, repo, "outlined report", report.ID, start)
+	review := insertTestTask(t, repo, "review PR", uuid.Nil, start.Add(time.Minute))
+	insertTestTask(t, repo, "reported the flaky bug in CI", review.ID, start.Add(time.Minute))
+	renamed := insertTestTask(t, repo, "report card", uuid.Nil, start.Add(2*time.Minute))
+	renamed.Name = "grade papers"
+	if _, err := repo.UpdateTask(ctx, renamed.ID, renamed.TaskRecord); err != nil {
+		t.Fatal(err)
+	}
+	deleted := insertTestTask(t, repo, "obsolete memo", uuid.Nil, start.Add(3*time.Minute))
+	if _, err := repo.DeleteTasks(ctx, []any{deletedFirst.ID.String(), deleted.ID.String()}); err != nil {
+		t.Fatal(err)
+	}
+	// may renumber the tasks' rowids
+	if _, err := db(ctx).ExecContext(ctx, "VACUUM"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{name: "name or child", query: "report", limit: 10, want: []string{"write report", "review PR"}},
+		{name: "limit", query: "report", limit: 1, want: []string{"write report"}},
+		{name: "renamed", query: "grade", limit: 10, want: []string{"grade papers"}},
+		{name: "old name", query: "card", limit: 10, want: nil},
+		{name: "deleted", query: "memo", limit: 10, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := repo.Search(ctx, tt.query, tt.limit)
+
+			// assert
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+			var names []string
+			for _, r := range got {
+				names = append(names, r.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, names)
+			}
+			for i := range tt.want {
+				if names[i] != tt.want[i] {
+					t.Fatalf("want %v, got %v", tt.want, names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	txStdLib "github.com/Thiht/transactor/stdlib"
+
+	"github.com/benjamonnguyen/daygo"
+)
+
+const (
+	SelectAllWebhooks    = "SELECT id, url, tags, secret, created_at FROM webhooks"
+	SelectAllDeadLetters = "SELECT id, webhook_id, event, payload, error, attempts, created_at FROM webhook_dead_letters"
+)
+
+type webhookEntity struct {
+	ID        int
+	URL       string
+	Tags      string
+	Secret    string
+	CreatedAt int64
+}
+
+type deadLetterEntity struct {
+	ID        int
+	WebhookID int
+	Event     string
+	Payload   string
+	Error     string
+	Attempts  int
+	CreatedAt int64
+}
+
+// webhookRepo
+type webhookRepo struct {
+	dbGetter txStdLib.DBGetter
+	l        daygo.Logger
+}
+
+var _ daygo.WebhookRepo = (*webhookRepo)(nil)
+
+func NewWebhookRepo(dbGetter txStdLib.DBGetter, logger daygo.Logger) daygo.WebhookRepo {
+	return &webhookRepo{
+		l:        logger,
+		dbGetter: dbGetter,
+	}
+}
+
+func (r *webhookRepo) GetWebhooks(ctx context.Context) ([]daygo.ExistingWebhookRecord, error) {
+	db := r.dbGetter(ctx)
+	rows, err := db.QueryContext(ctx, SelectAllWebhooks+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var webhooks []daygo.ExistingWebhookRecord
+	for rows.Next() {
+		webhook, err := extractWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepo) getWebhook(ctx context.Context, id int) (daygo.ExistingWebhookRecord, error) {
+	if id == 0 {
+		return daygo.ExistingWebhookRecord{}, fmt.Errorf("provide id")
+	}
+
+	db := r.dbGetter(ctx)
+	row := db.QueryRowContext(
+		ctx,
+		fmt.Sprintf("%s WHERE id=?", SelectAllWebhooks), id,
+	)
+
+	return extractWebhook(row)
+}
+
+func (r *webhookRepo) InsertWebhook(ctx context.Context, webhook daygo.WebhookRecord) (daygo.ExistingWebhookRecord, error) {
+	if webhook.URL == "" {
+		return daygo.ExistingWebhookRecord{}, fmt.Errorf("provide required field 'URL'")
+	}
+
+	existingRecord := daygo.ExistingWebhookRecord{
+		WebhookRecord: webhook,
+		CreatedAt:     time.Now(),
+	}
+	e := mapToWebhookEntity(existingRecord)
+
+	query := "INSERT INTO webhooks (url, tags, secret, created_at) VALUES (?, ?, ?, ?)"
+	r.l.Debug("creating webhook", "query", query, "url", e.URL, "tags", e.Tags)
+	result, err := r.dbGetter(ctx).ExecContext(ctx, query, e.URL, e.Tags, e.Secret, e.CreatedAt)
+	if err != nil {
+		return daygo.ExistingWebhookRecord{}, err
+	}
+
+	insertedID, err := result.LastInsertId()
+	if err != nil {
+		return daygo.ExistingWebhookRecord{}, err
+	}
+	existingRecord.ID = int(insertedID)
+
+	return existingRecord, nil
+}
+
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, id int) (daygo.ExistingWebhookRecord, error) {
+	existing, err := r.getWebhook(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+
+	query := "DELETE FROM webhooks WHERE id = ?"
+	r.l.Debug("deleting webhook", "query", query, "id", id)
+	if _, err := r.dbGetter(ctx).ExecContext(ctx, query, id); err != nil {
+		return daygo.ExistingWebhookRecord{}, err
+	}
+
+	return existing, nil
+}
+
+func (r *webhookRepo) GetDeadLetters(ctx context.Context) ([]daygo.ExistingWebhookDeadLetterRecord, error) {
+	db := r.dbGetter(ctx)
+	rows, err := db.QueryContext(ctx, SelectAllDeadLetters+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var deadLetters []daygo.ExistingWebhookDeadLetterRecord
+	for rows.Next() {
+		deadLetter, err := extractDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, rows.Err()
+}
+
+func (r *webhookRepo) InsertDeadLetter(ctx context.Context, deadLetter daygo.WebhookDeadLetterRecord) (daygo.ExistingWebhookDeadLetterRecord, error) {
+	if deadLetter.WebhookID == 0 {
+		return daygo.ExistingWebhookDeadLetterRecord{}, fmt.Errorf("provide required field 'WebhookID'")
+	}
+
+	existingRecord := daygo.ExistingWebhookDeadLetterRecord{
+		WebhookDeadLetterRecord: deadLetter,
+		CreatedAt:               time.Now(),
+	}
+	e := mapToDeadLetterEntity(existingRecord)
+
+	query := "INSERT INTO webhook_dead_letters (webhook_id, event, payload, error, attempts, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	r.l.Debug("creating webhook dead letter", "query", query, "webhookID", e.WebhookID, "event", e.Event)
+	result, err := r.dbGetter(ctx).ExecContext(ctx, query, e.WebhookID, e.Event, e.Payload, e.Error, e.Attempts, e.CreatedAt)
+	if err != nil {
+		return daygo.ExistingWebhookDeadLetterRecord{}, err
+	}
+
+	insertedID, err := result.LastInsertId()
+	if err != nil {
+		return daygo.ExistingWebhookDeadLetterRecord{}, err
+	}
+	existingRecord.ID = int(insertedID)
+
+	return existingRecord, nil
+}
+
+func extractWebhook(s scannable) (daygo.ExistingWebhookRecord, error) {
+	var e webhookEntity
+	if err := s.Scan(&e.ID, &e.URL, &e.Tags, &e.Secret, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return daygo.ExistingWebhookRecord{}, fmt.Errorf("failed to extract webhook: %w", ErrNotFound)
+		}
+		return daygo.ExistingWebhookRecord{}, err
+	}
+
+	return mapToExistingWebhookRecord(e), nil
+}
+
+func extractDeadLetter(s scannable) (daygo.ExistingWebhookDeadLetterRecord, error) {
+	var e deadLetterEntity
+	if err := s.Scan(&e.ID, &e.WebhookID, &e.Event, &e.Payload, &e.Error, &e.Attempts, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return daygo.ExistingWebhookDeadLetterRecord{}, fmt.Errorf("failed to extract webhook dead letter: %w", ErrNotFound)
+		}
+		return daygo.ExistingWebhookDeadLetterRecord{}, err
+	}
+
+	return daygo.ExistingWebhookDeadLetterRecord{
+		ID:        e.ID,
+		CreatedAt: time.Unix(e.CreatedAt, 0).Local(),
+		WebhookDeadLetterRecord: daygo.WebhookDeadLetterRecord{
+			WebhookID: e.WebhookID,
+			Event:     daygo.WebhookEventType(e.Event),
+			Payload:   e.Payload,
+			Error:     e.Error,
+			Attempts:  e.Attempts,
+		},
+	}, nil
+}
+
+func mapToWebhookEntity(webhook daygo.ExistingWebhookRecord) webhookEntity {
+	return webhookEntity{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Tags:      strings.Join(webhook.Tags, ","),
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt.Unix(),
+	}
+}
+
+func mapToExistingWebhookRecord(e webhookEntity) daygo.ExistingWebhookRecord {
+	var tags []string
+	if e.Tags != "" {
+		tags = strings.Split(e.Tags, ",")
+	}
+
+	return daygo.ExistingWebhookRecord{
+		ID:        e.ID,
+		CreatedAt: time.Unix(e.CreatedAt, 0).Local(),
+		WebhookRecord: daygo.WebhookRecord{
+			URL:    e.URL,
+			Tags:   tags,
+			Secret: e.Secret,
+		},
+	}
+}
+
+func mapToDeadLetterEntity(deadLetter daygo.ExistingWebhookDeadLetterRecord) deadLetterEntity {
+	return deadLetterEntity{
+		ID:        deadLetter.ID,
+		WebhookID: deadLetter.WebhookID,
+		Event:     string(deadLetter.Event),
+		Payload:   deadLetter.Payload,
+		Error:     deadLetter.Error,
+		Attempts:  deadLetter.Attempts,
+		CreatedAt: deadLetter.CreatedAt.Unix(),
+	}
+}
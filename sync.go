@@ -39,11 +39,13 @@ const (
 )
 
 type SyncRequest struct {
-	LastSyncTime time.Time            `json:"last_sync_time"`
-	ClientTasks  []ExistingTaskRecord `json:"client_tasks"`
+	LastSyncTime      time.Time                  `json:"last_sync_time"`
+	ClientTasks       []ExistingTaskRecord       `json:"client_tasks"`
+	ClientRecurrences []ExistingRecurrenceRecord `json:"client_recurrences"`
 }
 
 type SyncResponse struct {
-	ServerTasks       []ExistingTaskRecord `json:"server_tasks"`
-	ToServerSyncCount int                  `json:"to_server_sync_count"`
+	ServerTasks       []ExistingTaskRecord       `json:"server_tasks"`
+	ServerRecurrences []ExistingRecurrenceRecord `json:"server_recurrences"`
+	ToServerSyncCount int                        `json:"to_server_sync_count"`
 }
@@ -13,23 +13,70 @@ type TaskRepo interface {
 	GetTasks(context.Context, []any) ([]ExistingTaskRecord, error)
 	GetAllTasks(ctx context.Context) ([]ExistingTaskRecord, error)
 	GetByParentID(context.Context, uuid.UUID) ([]ExistingTaskRecord, error)
+	GetByContinuesID(context.Context, uuid.UUID) ([]ExistingTaskRecord, error)
 	// GetByStartTime returns tasks with null started_at if min and max are zero
 	GetByStartTime(ctx context.Context, min, max time.Time) ([]ExistingTaskRecord, error)
 	GetByCreateTime(ctx context.Context, min, max time.Time) ([]ExistingTaskRecord, error)
 	GetByUpdateTime(ctx context.Context, min, max time.Time) ([]ExistingTaskRecord, error)
+	// GetInProgress returns top-level tasks that are started but not ended,
+	// latest started first, excluding remote tasks
+	GetInProgress(ctx context.Context) ([]ExistingTaskRecord, error)
+	// GetLastEnded returns the top-level task that ended last
+	GetLastEnded(ctx context.Context) (ExistingTaskRecord, error)
+	// GetRecentNames returns distinct names of top-level tasks, most recently used first
+	GetRecentNames(ctx context.Context, limit int) ([]string, error)
+	// Search returns up to limit top-level tasks whose name or children's names
+	// match the query's words, most relevant first
+	Search(ctx context.Context, query string, limit int) ([]ExistingTaskRecord, error)
+	// GetStats aggregates the ended top-level tasks started within range per group
+	GetStats(ctx context.Context, min, max time.Time, groupBy StatsGroup) ([]TaskStatsRecord, error)
 
 	//
 	InsertTask(context.Context, TaskRecord) (ExistingTaskRecord, error)
+	// RestoreTask inserts a task keeping its ID and creation time, e.g. one
+	// exported from another database. It's updated now so that it syncs.
+	RestoreTask(context.Context, ExistingTaskRecord) (ExistingTaskRecord, error)
+	// UpdateTask updates the task, which is no longer remote
 	UpdateTask(context.Context, uuid.UUID, TaskRecord) (ExistingTaskRecord, error)
+	// MarkRemote flags the task as last written by sync from a server, e.g.
+	// started on another device, so that it isn't resumed as in progress here
+	MarkRemote(context.Context, uuid.UUID) error
 	DeleteTasks(context.Context, []any) ([]ExistingTaskRecord, error)
 }
 
 type TaskRecord struct {
-	Name      string
-	ParentID  uuid.UUID
-	StartedAt time.Time
-	EndedAt   time.Time
-	QueuedAt  time.Time
+	Name        string
+	ParentID    uuid.UUID
+	Kind        TaskKind
+	ContinuesID uuid.UUID // task this task is a follow-up of
+	Pomodoros   int       // completed pomodoro work intervals
+	StartedAt   time.Time
+	EndedAt     time.Time
+	QueuedAt    time.Time
+	TimeBlockAt time.Time // planned end of the task
+}
+
+type TaskKind int
+
+const (
+	TaskKindTask    TaskKind = iota // top-level task, or a note if ParentID is set
+	TaskKindSubtask                 // checklist item of ParentID; done if EndedAt is set
+)
+
+// StatsGroup is what aggregates are grouped by
+type StatsGroup int
+
+const (
+	StatsGroupNone StatsGroup = iota
+	StatsGroupDay             // local date as YYYY-MM-DD
+	StatsGroupTag             // tag without '#'; a task counts toward each of its tags
+)
+
+type TaskStatsRecord struct {
+	// Group is empty for StatsGroupNone and untagged tasks
+	Group   string
+	Count   int
+	Tracked time.Duration // excludes pauses
 }
 
 type ExistingTaskRecord struct {
@@ -0,0 +1,88 @@
+package daygo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type WebhookRepo interface {
+	GetWebhooks(context.Context) ([]ExistingWebhookRecord, error)
+	InsertWebhook(context.Context, WebhookRecord) (ExistingWebhookRecord, error)
+	DeleteWebhook(context.Context, int) (ExistingWebhookRecord, error)
+
+	GetDeadLetters(context.Context) ([]ExistingWebhookDeadLetterRecord, error)
+	InsertDeadLetter(context.Context, WebhookDeadLetterRecord) (ExistingWebhookDeadLetterRecord, error)
+}
+
+// WebhookRecord is a URL to send task events to. If Tags is set, only events
+// for tasks with any of the tags are sent. If Secret is set, events are signed.
+type WebhookRecord struct {
+	URL    string
+	Tags   []string
+	Secret string
+}
+
+type ExistingWebhookRecord struct {
+	WebhookRecord
+	ID        int
+	CreatedAt time.Time
+}
+
+// WebhookDeadLetterRecord is an event that couldn't be delivered to a webhook
+type WebhookDeadLetterRecord struct {
+	WebhookID int
+	Event     WebhookEventType
+	Payload   string
+	Error     string
+	Attempts  int
+}
+
+type ExistingWebhookDeadLetterRecord struct {
+	WebhookDeadLetterRecord
+	ID        int
+	CreatedAt time.Time
+}
+
+type WebhookEventType string
+
+const (
+	WebhookEventTaskCreated WebhookEventType = "task.created"
+	WebhookEventTaskUpdated WebhookEventType = "task.updated"
+)
+
+const (
+	WebhookEventHeader     = "X-Daygo-Event"
+	WebhookSignatureHeader = "X-Daygo-Signature"
+)
+
+type WebhookEvent struct {
+	Type WebhookEventType   `json:"type"`
+	Task ExistingTaskRecord `json:"task"`
+	Time time.Time          `json:"time"`
+}
+
+// SignWebhookPayload returns the value of the signature header for a payload:
+// the hex encoded HMAC-SHA256 of the payload prefixed with "sha256="
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type RegisterWebhookRequest struct {
+	URL    string   `json:"url"`
+	Tags   []string `json:"tags"`
+	Secret string   `json:"secret"`
+}
+
+// WebhookResponse describes a registered webhook without revealing its secret
+type WebhookResponse struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Tags      []string  `json:"tags"`
+	Signed    bool      `json:"signed"`
+	CreatedAt time.Time `json:"created_at"`
+}